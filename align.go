@@ -0,0 +1,91 @@
+package beta
+
+import "unicode"
+
+// An Alignment pairs a word of Betacode with the same word in Greek text.
+type Alignment struct {
+	Beta, Greek [2]int // Byte ranges [start, end) of the word
+}
+
+// How many words Align skips at most to find a counterpart
+const alignWindow = 8
+
+// Align pairs the words of Betacode source with the words of greek, a
+// conversion of it, possibly by other means or an older version: differences
+// of normalisation, of oxia versus tonos and of final sigma are ignored. Words
+// without a counterpart among the next few words, where the texts differ, are
+// left out. It fails if the Betacode does not parse.
+func Align(betacode, greek string) ([]Alignment, error) {
+	words, err := Words(betacode)
+	if err != nil {
+		return nil, err
+	}
+
+	const f = FoldNorm | FoldTonos | FoldSigma
+	src := make([]string, len(words))
+	for i, w := range words {
+		src[i] = fold(w.Greek(), f)
+	}
+	dst := greekWords(greek)
+	dstFold := make([]string, len(dst))
+	for i, r := range dst {
+		dstFold[i] = fold(greek[r[0]:r[1]], f)
+	}
+
+	var pairs []Alignment
+	i, j := 0, 0
+	for i < len(src) && j < len(dst) {
+		if src[i] == dstFold[j] {
+			w := words[i]
+			pairs = append(pairs, Alignment{
+				Beta:  [2]int{w.Pos.Offset, w.Pos.Offset + len(w.Source)},
+				Greek: dst[j],
+			})
+			i++
+			j++
+			continue
+		}
+
+		// Skip the words missing on one side, or else a changed word.
+		di, dj := 1, 1
+		for d := 1; d <= alignWindow; d++ {
+			if i+d < len(src) && src[i+d] == dstFold[j] {
+				di, dj = d, 0
+				break
+			}
+			if j+d < len(dst) && src[i] == dstFold[j+d] {
+				di, dj = 0, d
+				break
+			}
+		}
+		i += di
+		j += dj
+	}
+
+	return pairs, nil
+}
+
+// greekWords returns the byte ranges of the words of greek: runs of letters
+// and combining marks.
+func greekWords(greek string) [][2]int {
+	var ranges [][2]int
+
+	start := -1
+	for i, r := range greek {
+		if unicode.IsLetter(r) || unicode.Is(unicode.Mn, r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			ranges = append(ranges, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		ranges = append(ranges, [2]int{start, len(greek)})
+	}
+
+	return ranges
+}
@@ -0,0 +1,31 @@
+package beta
+
+import "testing"
+
+func TestAlign(t *testing.T) {
+	const betacode = "mh=nin a)/eide qea/ Phlhi+a/dew"
+
+	// Decomposed, with an oxia instead of tonos, one word dropped and one
+	// inserted.
+	const greek = "\u03BC\u1FC6\u03BD\u03B9\u03BD \u03B1\u0313\u0301\u03B5\u03B9\u03B4\u03B5 x \u03A0\u03B7\u03BB\u03B7\u03CA\u1F71\u03B4\u03B5\u03C9"
+
+	pairs, err := Align(betacode, greek)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref := []struct{ beta, greek string }{
+		{"mh=nin", "\u03BC\u1FC6\u03BD\u03B9\u03BD"},
+		{"a)/eide", "\u03B1\u0313\u0301\u03B5\u03B9\u03B4\u03B5"},
+		{"Phlhi+a/dew", "\u03A0\u03B7\u03BB\u03B7\u03CA\u1F71\u03B4\u03B5\u03C9"},
+	}
+	if len(pairs) != len(ref) {
+		t.Fatalf("expected %d pairs, got %v", len(ref), pairs)
+	}
+	for i, p := range pairs {
+		b, g := betacode[p.Beta[0]:p.Beta[1]], greek[p.Greek[0]:p.Greek[1]]
+		if b != ref[i].beta || g != ref[i].greek {
+			t.Errorf("pair %d: expected %q, %q, got %q, %q", i, ref[i].beta, ref[i].greek, b, g)
+		}
+	}
+}
@@ -24,6 +24,7 @@ package beta
 import (
 	"errors"
 	"strings"
+	"sync"
 	"unicode"
 
 	"golang.org/x/text/unicode/norm"
@@ -89,6 +90,23 @@ func validTrema(r rune) error {
 	return nil
 }
 
+// strictErr returns an error if sym is valid Betacode, but cannot occur in
+// Greek.
+func strictErr(sym Sym) error {
+	b := unicode.ToLower(sym.Base)
+	if sym.Accent == '=' && (b == 'e' || b == 'o') {
+		return errors.New("can't put circumflex on short vowel")
+	}
+	if sym.Iota && !strings.ContainsRune("ahw", b) {
+		return errors.New("can't put iota subscriptum on vowels other than alpha, eta and omega")
+	}
+	if sym.Trema && b != 'i' && b != 'u' {
+		return errors.New("can't put trema on vowels other than iota and upsilon")
+	}
+
+	return nil
+}
+
 // Reset clears the Sym so that it can be re-used.
 func (sym *Sym) Reset() {
 	sym.Base = 0
@@ -227,13 +245,13 @@ func (sym Sym) Err() error {
 // Precombined returns the NFC normalised Unicode form (precombined code point)
 // as a UTF-8 byte slice. This is the usual form.
 func (sym Sym) Precombined() []byte {
-	return norm.NFC.Bytes(sym.Combining())
+	return []byte(precombined(sym, false))
 }
 
 // PrecombinedString returns the NFC normalised Unicode form (precombined code point)
 // as a UTF-8 string. This is the usual form.
 func (sym Sym) PrecombinedString() string {
-	return string(sym.Precombined())
+	return precombined(sym, false)
 }
 
 // Combining returns the combining diacritics Unicode form as a UTF-8 byte slice.
@@ -243,31 +261,111 @@ func (sym Sym) Combining() []byte {
 
 // CombiningString returns the combining diacritics Unicode form as a UTF-8 string.
 func (sym Sym) CombiningString() string {
-	var s string
+	return sym.combining(false)
+}
+
+// Forms returns both the precombined and the combining diacritics forms as
+// UTF-8 byte slices, for indexing decomposed text while displaying
+// precombined text.
+func (sym Sym) Forms() (nfc, combining []byte) {
+	return sym.Precombined(), sym.appendCombining(nil, false)
+}
+
+// Canonical is like Combining, but with the marks in canonical order.
+func (sym Sym) Canonical() []byte {
+	return []byte(sym.CanonicalString())
+}
+
+// CanonicalString is like CombiningString, but puts the marks in canonical
+// order (breathing, diaeresis, accent, iota subscript), the order of the
+// decompositions of the precombined characters. Normalization doesn't change
+// it, and NFC composes it completely, e.g. to ΐ.
+func (sym Sym) CanonicalString() string {
+	return sym.combining(true)
+}
+
+func (sym Sym) combining(canonical bool) string {
+	return string(sym.appendCombining(nil, canonical))
+}
 
+// appendCombining appends the combining diacritics Unicode form to dst.
+func (sym Sym) appendCombining(dst []byte, canonical bool) []byte {
 	// An uppercase Betacode letter is treated as a lowercase one to
 	if unicode.IsUpper(sym.Base) {
 		lowerBase := unicode.ToLower(sym.Base)
-		s += string(unicode.ToUpper(code[lowerBase]))
+		dst = appendRune(dst, unicode.ToUpper(code[lowerBase]))
 	} else {
-		s += string(code[sym.Base])
+		dst = appendRune(dst, code[sym.Base])
 	}
 
 	if sym.Spiritus != 0 {
-		s += string(code[sym.Spiritus])
+		dst = appendRune(dst, code[sym.Spiritus])
+	}
+	if canonical && sym.Trema {
+		dst = appendRune(dst, code['+'])
 	}
 	if sym.Accent != 0 {
-		s += string(code[sym.Accent])
+		dst = appendRune(dst, code[sym.Accent])
 	}
 	if sym.Iota {
-		s += string(code['|'])
+		dst = appendRune(dst, code['|'])
 	}
-	if sym.Trema {
-		s += string(code['+'])
+	if !canonical && sym.Trema {
+		dst = appendRune(dst, code['+'])
 	}
-	return s
+	return dst
+}
+
+// The precombined forms of all symbols, computed on first use, since
+// normalising each symbol anew is slow and allocates
+var (
+	precombinedOnce  sync.Once
+	precombinedForms map[precombinedKey]string
+)
+
+type precombinedKey struct {
+	sym       Sym // Without ast and err
+	canonical bool
+}
+
+// precombined returns the NFC form of sym with the marks in combining or,
+// if canonical, in canonical order.
+func precombined(sym Sym, canonical bool) string {
+	precombinedOnce.Do(func() {
+		precombinedForms = map[precombinedKey]string{}
+		for b := range code {
+			if !unicode.IsLetter(b) {
+				continue
+			}
+			for _, acc := range []rune{0, '/', '\\', '='} {
+				for _, sp := range []rune{0, ')', '('} {
+					for _, flags := range []int{0, 1, 2, 3} {
+						sym := Sym{Base: b, Accent: acc, Spiritus: sp, Iota: flags&1 != 0, Trema: flags&2 != 0}
+						for _, c := range []bool{false, true} {
+							precombinedForms[precombinedKey{sym, c}] = norm.NFC.String(sym.combining(c))
+						}
+					}
+				}
+			}
+		}
+	})
+
+	key := precombinedKey{Sym{Base: sym.Base, Accent: sym.Accent, Spiritus: sym.Spiritus, Iota: sym.Iota, Trema: sym.Trema}, canonical}
+	if s, ok := precombinedForms[key]; ok {
+		return s
+	}
+	return norm.NFC.String(sym.combining(canonical))
 }
 
+// code maps Betacode to Greek letters and combining diacritics. It is written
+// by hand rather than generated from the Unicode Character Database, so no
+// Unicode version can be chosen for it. Its characters, and the precombined
+// characters NFC composes them to, date from Unicode 1.1, but for the small
+// digamma U+03DD of v, from Unicode 3.0; newer characters like U+0370 HETA
+// are not produced. Options bring in others: the capital lunate sigma U+03F9
+// of SigmaLunate and s3 dates from Unicode 4.0, and the signs of DigitsCodes
+// include the small koppa U+03DF and small stigma U+03DB of Unicode 3.0 and
+// the archaic koppa U+03D9 and bracket U+27E6 of Unicode 3.2.
 var code = map[rune]rune{
 	'A': 'Α',
 	'B': 'Β',
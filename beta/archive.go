@@ -0,0 +1,140 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archiveFormat returns the extension of the archive format of the named
+// file, which may be compressed, or "" if it is no archive.
+func archiveFormat(name string) string {
+	base := strings.TrimSuffix(name, compression(name))
+	switch ext := filepath.Ext(base); ext {
+	case ".zip", ".tar":
+		return ext
+	}
+	if filepath.Ext(name) == ".tgz" {
+		return ".tar"
+	}
+	return ""
+}
+
+// archive converts the regular members of the named archive and writes the
+// new archive, with the same member names and metadata, to w. It returns the
+// exit status.
+func (c *converter) archive(name string, w io.Writer) int {
+	var status int
+	var err error
+	if archiveFormat(name) == ".zip" {
+		status, err = c.zip(name, w)
+	} else {
+		status, err = c.tar(name, w)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "beta: %s: %v\n", name, err)
+		return 2
+	}
+	return status
+}
+
+// member converts an archive member.
+func (c *converter) member(name string, r io.Reader) ([]byte, int) {
+	var buf bytes.Buffer
+	status := c.stream(name, r, func(line int, text string) error {
+		buf.WriteString(text)
+		return nil
+	})
+	return buf.Bytes(), status
+}
+
+func (c *converter) zip(name string, w io.Writer) (int, error) {
+	src, err := readFile(name)
+	if err != nil {
+		return 0, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(src), int64(len(src)))
+	if err != nil {
+		return 0, err
+	}
+
+	status := 0
+	zw := zip.NewWriter(w)
+	for _, f := range zr.File {
+		hdr := f.FileHeader
+		hdr.CRC32, hdr.CompressedSize64, hdr.UncompressedSize64 = 0, 0, 0
+		fw, err := zw.CreateHeader(&hdr)
+		if err != nil {
+			return 0, err
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return 0, err
+		}
+		p, s := c.member(name+":"+f.Name, rc)
+		rc.Close()
+		if s > status {
+			status = s
+		}
+		if _, err := fw.Write(p); err != nil {
+			return 0, err
+		}
+	}
+
+	return status, zw.Close()
+}
+
+func (c *converter) tar(name string, w io.Writer) (int, error) {
+	r, err := openInput(name)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	status := 0
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			if err := tw.WriteHeader(hdr); err != nil {
+				return 0, err
+			}
+			if _, err := io.Copy(tw, tr); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		p, s := c.member(name+":"+hdr.Name, tr)
+		if s > status {
+			status = s
+		}
+		hdr.Size = int64(len(p))
+		if err := tw.WriteHeader(hdr); err != nil {
+			return 0, err
+		}
+		if _, err := tw.Write(p); err != nil {
+			return 0, err
+		}
+	}
+
+	return status, tw.Close()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/okitec/beta"
+)
+
+// bench converts the files of the directories in args repeatedly, reports
+// throughput, allocations and errors and returns the exit status.
+func bench(args []string) int {
+	flags := flag.NewFlagSet("beta bench", flag.ExitOnError)
+	n := flags.Int("n", 10, "convert the corpus `count` times")
+	ext := flags.String("ext", ".beta", "`extension` of the files converted in directories")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 || *n < 1 {
+		fmt.Fprintln(os.Stderr, "usage: beta bench [-n count] [-ext ext] dir...")
+		return 2
+	}
+
+	files, err := inputFiles(flags.Args(), true, *ext)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+
+	var corpus [][]byte
+	size := 0
+	for _, name := range files {
+		p, err := readFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", err)
+			return 2
+		}
+		corpus = append(corpus, p)
+		size += len(p)
+	}
+
+	// Invalid Betacode is skipped, as it is when converting, so that a
+	// corpus with errors can still be measured.
+	w := beta.NewWriter(ioutil.Discard)
+	w.Handler = func(error, beta.Position, string) beta.Action { return beta.Skip }
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	for i := 0; i < *n; i++ {
+		for _, p := range corpus {
+			w.Reset(ioutil.Discard)
+			w.Write(p)
+			if err := w.Flush(); err != nil {
+				fmt.Fprintln(os.Stderr, "beta:", err)
+				return 2
+			}
+		}
+	}
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	// The errors are those of the last run, which are the same as those of
+	// any other.
+	errors := 0
+	for _, p := range corpus {
+		w.Reset(ioutil.Discard)
+		w.Write(p)
+		w.Flush()
+		errors += w.Stats().Errors
+	}
+
+	total := float64(size) * float64(*n)
+	fmt.Printf("%d files, %d bytes, %d runs\n", len(files), size, *n)
+	fmt.Printf("%.2f MB/s\n", total/1e6/elapsed.Seconds())
+	fmt.Printf("%.1f allocs/run, %.0f bytes/run\n",
+		float64(after.Mallocs-before.Mallocs)/float64(*n),
+		float64(after.TotalAlloc-before.TotalAlloc)/float64(*n))
+	fmt.Printf("%d errors\n", errors)
+
+	if errors > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// cache records the content hashes of the files converted in place, so that
+// they are skipped by later runs until they change.
+type cache struct {
+	name   string
+	hashes map[string]string // path to SHA-256 of the converted file
+}
+
+// loadCache reads the named cache file; a missing file is an empty cache.
+func loadCache(name string) (*cache, error) {
+	c := &cache{name: name, hashes: map[string]string{}}
+
+	p, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(p, &c.hashes); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// upToDate reports whether the named file is unchanged since its conversion.
+func (c *cache) upToDate(name string) bool {
+	h, ok := c.hashes[name]
+	if !ok {
+		return false
+	}
+	cur, err := hashFile(name)
+	return err == nil && cur == h
+}
+
+// record notes that the named file has been converted.
+func (c *cache) record(name string) error {
+	h, err := hashFile(name)
+	if err != nil {
+		return err
+	}
+	c.hashes[name] = h
+	return nil
+}
+
+// save writes the cache back to its file.
+func (c *cache) save() error {
+	p, err := json.MarshalIndent(c.hashes, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.name, append(p, '\n'), 0666)
+}
+
+func hashFile(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
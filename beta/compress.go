@@ -0,0 +1,143 @@
+package main
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Compression formats by file extension. Formats without a Go implementation
+// are handled by the external tool.
+var compressors = map[string]string{
+	".gz":  "",
+	".tgz": "",
+	".bz2": "bzip2",
+	".zst": "zstd",
+}
+
+// compression returns the extension of the compression format of the named
+// file, or "" if it is not compressed.
+func compression(name string) string {
+	ext := filepath.Ext(name)
+	if _, ok := compressors[ext]; ok {
+		return ext
+	}
+	return ""
+}
+
+// openInput opens the named file, or standard input for "-", and
+// decompresses it according to its extension.
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.ReadCloser
+	switch ext := compression(name); ext {
+	case "":
+		return f, nil
+	case ".gz", ".tgz":
+		r, err = gzip.NewReader(f)
+	case ".bz2":
+		r = ioutil.NopCloser(bzip2.NewReader(f))
+	default:
+		r, err = filter(compressors[ext], f, "-dc")
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return readCloser{r, f}, nil
+}
+
+// compressOutput returns a writer that compresses to w in the format ext.
+// It must be closed to complete the output.
+func compressOutput(w io.Writer, ext string) (io.WriteCloser, error) {
+	switch ext {
+	case "":
+		return nopWriteCloser{w}, nil
+	case ".gz", ".tgz":
+		return gzip.NewWriter(w), nil
+	}
+
+	cmd := exec.Command(compressors[ext], "-c")
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &cmdWriter{in, cmd}, nil
+}
+
+// filter runs tool with args on the input r and returns its output.
+func filter(tool string, r io.Reader, args ...string) (io.ReadCloser, error) {
+	cmd := exec.Command(tool, args...)
+	cmd.Stdin = r
+	cmd.Stderr = os.Stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &cmdReader{out, cmd}, nil
+}
+
+// readCloser closes both the decompressor and the file.
+type readCloser struct {
+	io.ReadCloser
+	f *os.File
+}
+
+func (rc readCloser) Close() error {
+	rc.ReadCloser.Close()
+	return rc.f.Close()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// cmdReader is the output of an external tool.
+type cmdReader struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (cr *cmdReader) Close() error {
+	cr.ReadCloser.Close()
+	return cr.cmd.Wait()
+}
+
+// cmdWriter is the input of an external tool.
+type cmdWriter struct {
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+func (cw *cmdWriter) Close() error {
+	if err := cw.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return cw.cmd.Wait()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/okitec/beta"
+)
+
+// format formats Betacode files like gofmt and returns the exit status.
+func format(args []string) int {
+	flags := flag.NewFlagSet("beta fmt", flag.ExitOnError)
+	from := flags.String("from", "typegreek", "read the files as Betacode of `dialect`: typegreek, standard, tlg or keyboard")
+	ignoreCase := flags.Bool("ignore-case", false, "make capitals only of letters after an asterisk, whatever their case")
+	codes := flags.Bool("codes", false, "read and write digits after s, [, ], \", % and # as Standard Betacode codes")
+	dialect := flags.String("dialect", "typegreek", "write capitals and sigmas as in `dialect`: typegreek, standard, tlg or keyboard")
+	width := flags.Int("width", 0, "wrap lines longer than `n` runes at spaces")
+	list := flags.Bool("l", false, "list the files whose formatting differs instead of printing them")
+	write := flags.Bool("w", false, "replace the files by their formatting")
+	flags.Parse(args)
+
+	var opts beta.FormatOptions
+	var err error
+	if opts.Dialect, err = beta.ParseDialect(*dialect); err == nil {
+		opts.Options.Dialect, err = beta.ParseDialect(*from)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+	opts.Options.IgnoreCase = *ignoreCase
+	if *codes {
+		opts.Options.Digits = beta.DigitsCodes
+	}
+	opts.Width = *width
+
+	files := flags.Args()
+	if len(files) == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "beta: can't use -w on standard input")
+			return 2
+		}
+		files = []string{"-"}
+	}
+
+	status := 0
+	for _, name := range files {
+		var src []byte
+		if name == "-" {
+			src, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			src, err = ioutil.ReadFile(name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", err)
+			status = 2
+			continue
+		}
+
+		out, err := beta.Format(string(src), opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", name+":", err)
+			if status == 0 {
+				status = 1
+			}
+			continue
+		}
+
+		switch {
+		case *list:
+			if out != string(src) {
+				fmt.Println(name)
+			}
+		case *write:
+			if out == string(src) {
+				continue
+			}
+			if err := replaceFile(name, out); err != nil {
+				fmt.Fprintln(os.Stderr, "beta:", err)
+				status = 2
+			}
+		default:
+			os.Stdout.WriteString(out)
+		}
+	}
+
+	return status
+}
+
+// replaceFile replaces the named file by text, by way of a temporary file
+// in the same directory, so that the file is never left half written.
+func replaceFile(name, text string) error {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".beta")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(text)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), fi.Mode())
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	return err
+}
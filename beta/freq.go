@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/okitec/beta"
+)
+
+// freq counts the symbols of the files, streaming, and prints them by
+// descending count. It returns the exit status.
+func freq(args []string) int {
+	flags := flag.NewFlagSet("beta freq", flag.ExitOnError)
+	recursive := flags.Bool("r", false, "count the files in directories recursively")
+	ext := flags.String("ext", ".beta", "`extension` of the files counted in directories")
+	flags.Parse(args)
+
+	files, err := inputFiles(flags.Args(), *recursive, *ext)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	// Invalid Betacode is skipped, so that a corpus with errors can still
+	// be counted.
+	f := beta.NewFrequencies()
+	w := beta.NewSinkWriter(f)
+	w.Handler = func(error, beta.Position, string) beta.Action { return beta.Skip }
+	status := 0
+
+	for _, name := range files {
+		r, err := openInput(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", err)
+			status = 2
+			continue
+		}
+		_, err = io.Copy(w, r)
+		r.Close()
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", name+":", err)
+			status = 2
+		}
+		if w.Stats().Errors > 0 {
+			status = 1
+		}
+	}
+
+	fmt.Printf("%d symbols, %d words\n", f.Symbols, f.Words)
+	for _, c := range f.Ranked() {
+		fmt.Printf("%s\t%s\t%d\t%.3f%%\n", c.Sym.PrecombinedString(), c.Sym.String(), c.N, 100*float64(c.N)/float64(f.Symbols))
+	}
+	return status
+}
@@ -1,21 +1,579 @@
 // Command beta reads Betacode lines and spews out precombined Greek.
+//
+// Usage:
+//
+//	beta [-format text|json] [-verbatim] [-level level] [-dialect dialect] [-ignore-case] [-codes] [-rules file] [-bidi] [-hyphenate] [-verify-nfc] [-W] [-i [-cache file]] [-r] [-ext ext] [-z gz|bz2|zst] [-files0 file] [-manifest file] [-dry-run] [-coverage] [file...]
+//	beta proof [-format text|json] [file...]
+//	beta tokens [file...]
+//	beta bench [-n count] [-ext ext] dir...
+//	beta freq [-r] [-ext ext] [file...]
+//	beta fmt [-from dialect] [-ignore-case] [-codes] [-dialect dialect] [-width n] [-l | -w] [file...]
+//	beta selftest
+//
+// Without a subcommand, beta converts the files (or standard input) to
+// Greek on standard output. Invalid Betacode is reported, with its word
+// and the Greek of the word before it, and skipped; with -verbatim, words
+// containing it are copied unchanged instead. With -level
+// standard, Betacode that cannot be Greek, like e=, is invalid too;
+// pedantic also checks the position of breathings. The dialect is
+// typegreek, standard, tlg (Standard Betacode in capitals) or keyboard
+// (the letters of the Greek keyboard layout, like u for theta); without
+// -dialect, it is guessed from the start of each file. With -ignore-case,
+// only asterisks make capitals, whatever the case of the letters. With
+// -codes, digits after s, [, ], ", % and # are read as the codes of Standard
+// Betacode, like s1 for medial sigma and [1 for a parenthesis. With
+// -rules, the substitutions in the file are applied to the input first;
+// see beta.ParseRules for their syntax. With -bidi, right-to-left text in
+// the output, like Hebrew in literal regions, is isolated by bidi controls.
+// With -hyphenate, soft hyphens are put at the hyphenation points of the
+// Greek words, for justified typesetting. With -verify-nfc, output that
+// is not in NFC, or Greek without a precomposed character, is an error.
+// With -W, unusual input that is converted nonetheless, like a second accent
+// on a letter, is reported as a warning; warnings don't change the exit
+// status.
+// With -i, each file is replaced by its conversion, unless invalid
+// Betacode in it would be skipped, in which case it is kept; with -cache, the hashes
+// of converted files are recorded, and files that haven't changed since are
+// skipped on later runs. With -r, directories are
+// searched recursively for files with the extension ext (by default .beta).
+// With -z, standard output is compressed. With -files0, the names of further
+// files are read from a file, separated by NUL bytes as by find -print0.
+// With -manifest, a JSON manifest of the conversion is written to a file:
+// for every input file, its output (- for standard output), their sizes in
+// bytes, the number of errors, the exit status and the SHA-256 of the
+// output. A file that -i could not replace is listed with its status and
+// the reason, but without output.
+// With -dry-run, nothing is written; instead, each file is listed as it
+// would be converted, skipped (as up to date) or fail. With -coverage, the
+// files are taken to be Greek, and the characters in them that have no
+// Betacode are listed instead.
+// The members of .zip and .tar archives are converted into a new archive,
+// which is written to standard output or, with -i, replaces the original.
+// The proof subcommand checks Betacode files (or standard input) for
+// linguistically unusual words and prints a warning for each.
+// The tokens subcommand prints the parsed words with their symbols,
+// positions and converted forms as JSON lines.
+// The bench subcommand converts the files with extension ext in the
+// directories count times and reports the throughput, the allocations
+// and the number of errors.
+// The freq subcommand counts the symbols of the files, with their
+// diacritics, and prints them by descending count.
+// The fmt subcommand formats uncompressed Betacode files like gofmt:
+// the symbols with their diacritics in the order of beta.Sym.String,
+// capitals and sigmas as in the dialect, blanks collapsed and, with -width,
+// long lines wrapped. The files are read as Betacode of the -from dialect. With -l, the files whose formatting
+// differs are listed; with -w, they are replaced by way of a temporary file.
+// The selftest subcommand converts a reference corpus built into the
+// binary, Iliad 1.1-10 in each dialect, to Greek and back and compares the
+// results with the expected ones, so that packagers can check that an
+// installed binary converts as it should.
+//
+// Files ending in .gz, .bz2 or .zst are decompressed, and compressed again
+// when converted in place. The zstd format and writing bzip2 require the
+// zstd and bzip2 tools.
+//
+// With -format json, results and diagnostics are printed as JSON lines
+// for consumption by editors and CI systems.
 package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/okitec/beta"
 )
 
 func main() {
-	scanner := bufio.NewScanner(os.Stdin)
-	w := beta.NewWriter(os.Stdout)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "proof":
+			os.Exit(proof(os.Args[2:]))
+		case "tokens":
+			os.Exit(tokens(os.Args[2:]))
+		case "bench":
+			os.Exit(bench(os.Args[2:]))
+		case "freq":
+			os.Exit(freq(os.Args[2:]))
+		case "fmt":
+			os.Exit(format(os.Args[2:]))
+		case "selftest":
+			os.Exit(selftest(os.Args[2:]))
+		}
+	}
+	os.Exit(convert(os.Args[1:]))
+}
+
+// convert converts the files given in args and returns the exit status.
+func convert(args []string) int {
+	flags := flag.NewFlagSet("beta", flag.ExitOnError)
+	format := flags.String("format", "text", "output `format`: text or json")
+	verbatim := flags.Bool("verbatim", false, "copy invalid words unchanged instead of skipping the offending runes")
+	level := flags.String("level", "permissive", "validation `level`: permissive, standard or pedantic")
+	dialect := flags.String("dialect", "", "Betacode `dialect`: typegreek, standard, tlg or keyboard (default: detected)")
+	ignoreCase := flags.Bool("ignore-case", false, "make capitals only of letters after an asterisk, whatever their case")
+	codes := flags.Bool("codes", false, "read digits after s, [, ], \", % and # as Standard Betacode codes")
+	rulesFile := flags.String("rules", "", "apply the substitutions in `file` to the input")
+	bidi := flags.Bool("bidi", false, "isolate right-to-left text in the output by bidi controls")
+	hyphenate := flags.Bool("hyphenate", false, "put soft hyphens at the hyphenation points of the Greek words")
+	verifyNFC := flags.Bool("verify-nfc", false, "fail on output that is not in NFC or has no precomposed character")
+	warn := flags.Bool("W", false, "report warnings about unusual but convertible input")
+	inPlace := flags.Bool("i", false, "replace the files by their conversion")
+	recursive := flags.Bool("r", false, "convert the files in directories recursively")
+	ext := flags.String("ext", ".beta", "`extension` of the files converted in directories")
+	z := flags.String("z", "", "compress standard output: gz, bz2 or zst")
+	cacheFile := flags.String("cache", "", "with -i, skip the files recorded in the cache `file` as converted and unchanged")
+	dryRun := flags.Bool("dry-run", false, "list the files that would be converted, skipped or fail, without writing anything")
+	files0 := flags.String("files0", "", "also convert the files named in `file` (- for standard input), separated by NUL bytes")
+	coverage := flags.Bool("coverage", false, "list the characters of Greek files that have no Betacode")
+	manifestFile := flags.String("manifest", "", "write a JSON manifest of the conversion to `file`")
+	flags.Parse(args)
+
+	c := &converter{out: newOutput(*format), bidi: *bidi, hyphenate: *hyphenate}
+	c.opts.IgnoreCase = *ignoreCase
+	c.opts.VerifyNFC = *verifyNFC
+	c.out.warnStderr = true
+	c.warn = *warn
+	if *codes {
+		c.opts.Digits = beta.DigitsCodes
+	}
+	if *rulesFile != "" {
+		f, err := os.Open(*rulesFile)
+		if err == nil {
+			c.opts.Rules, err = beta.ParseRules(f)
+			f.Close()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", *rulesFile+":", err)
+			return 2
+		}
+	}
+	if *verbatim {
+		c.opts.Recovery = beta.RecoverVerbatim
+	}
+	var err error
+	if c.opts.Level, err = beta.ParseLevel(*level); err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+	if *dialect == "" {
+		c.detect = true
+	} else if c.opts.Dialect, err = beta.ParseDialect(*dialect); err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+
+	names := flags.Args()
+	if *files0 != "" {
+		p, err := readFile(*files0)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", err)
+			return 2
+		}
+		for _, name := range strings.Split(string(p), "\x00") {
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	files, err := inputFiles(names, *recursive, *ext)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+
+	var ca *cache
+	if *inPlace && *cacheFile != "" {
+		if ca, err = loadCache(*cacheFile); err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", err)
+			return 2
+		}
+	}
+
+	if *dryRun {
+		return c.dryRun(files, ca)
+	}
+	if *coverage {
+		if len(files) == 0 {
+			files = []string{"-"}
+		}
+		return listCoverage(files)
+	}
+
+	var m *manifest
+	if *manifestFile != "" {
+		m = &manifest{name: *manifestFile}
+	}
+
+	if *inPlace {
+		status := 0
+		for _, name := range files {
+			if ca != nil && ca.upToDate(name) {
+				if m != nil {
+					e := manifestEntry{Input: name, Output: name, InputBytes: fileSize(name), Skipped: true}
+					if err := m.add(e, nil); err != nil {
+						fmt.Fprintln(os.Stderr, "beta:", err)
+						status = 2
+					}
+				}
+				continue
+			}
+
+			c.errors = 0
+			size := fileSize(name)
+			s := c.inPlace(name)
+			if s > status {
+				status = s
+			}
+			if ca != nil && s == 0 {
+				if err := ca.record(name); err != nil {
+					fmt.Fprintln(os.Stderr, "beta:", err)
+					status = 2
+				}
+			}
+			if m != nil {
+				e := manifestEntry{Input: name, Output: name, InputBytes: size, Errors: c.errors, Status: s}
+				switch s {
+				case 0:
+					if err := m.add(e, nil); err != nil {
+						fmt.Fprintln(os.Stderr, "beta:", err)
+						status = 2
+					}
+				case 1:
+					e.Error = "invalid Betacode; file kept"
+					m.addFailed(e)
+				default:
+					e.Error = "conversion failed"
+					m.addFailed(e)
+				}
+			}
+		}
+
+		if m != nil {
+			if err := m.save(); err != nil {
+				fmt.Fprintln(os.Stderr, "beta:", err)
+				return 2
+			}
+		}
+		if ca != nil {
+			if err := ca.save(); err != nil {
+				fmt.Fprintln(os.Stderr, "beta:", err)
+				return 2
+			}
+		}
+		return status
+	}
+
+	if *z != "" {
+		if compression("."+*z) == "" {
+			fmt.Fprintln(os.Stderr, "beta: unknown compression", *z)
+			return 2
+		}
+	}
+	stdout, err := compressOutput(os.Stdout, compression("."+*z))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+
+	if len(files) == 0 && *files0 == "" {
+		files = []string{"-"}
+	}
+
+	status := 0
+	for _, name := range files {
+		var w io.Writer = stdout
+		hw := newHashWriter(stdout)
+		if m != nil {
+			w = hw
+		}
+
+		c.errors = 0
+		var s int
+		if archiveFormat(name) != "" {
+			s = c.archive(name, w)
+		} else {
+			s = c.file(name, func(line int, text string) error {
+				return c.out.result(w, name, line, text)
+			})
+		}
+		if s > status {
+			status = s
+		}
+		if m != nil {
+			e := manifestEntry{Input: name, Output: "-", InputBytes: fileSize(name), Errors: c.errors, Status: s}
+			if err := m.add(e, hw); err != nil {
+				fmt.Fprintln(os.Stderr, "beta:", err)
+				status = 2
+			}
+		}
+	}
+
+	if err := stdout.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+	if m != nil {
+		if err := m.save(); err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", err)
+			return 2
+		}
+	}
+	return status
+}
+
+// dryRun prints for each file whether it would be converted, skipped as up
+// to date according to ca, if not nil, or fail, and returns the exit status.
+func (c *converter) dryRun(files []string, ca *cache) int {
+	status := 0
+	for _, name := range files {
+		if ca != nil && ca.upToDate(name) {
+			fmt.Println("skip", name)
+			continue
+		}
+
+		var s int
+		if archiveFormat(name) != "" {
+			s = c.archive(name, ioutil.Discard)
+		} else {
+			s = c.file(name, func(int, string) error { return nil })
+		}
+		if s > status {
+			status = s
+		}
+
+		if s == 0 {
+			fmt.Println("convert", name)
+		} else {
+			fmt.Println("fail", name)
+		}
+	}
+
+	return status
+}
+
+// listCoverage prints the characters of the Greek files that have no
+// Betacode and returns the exit status.
+func listCoverage(files []string) int {
+	status := 0
+	for _, name := range files {
+		p, err := readFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", err)
+			status = 2
+			continue
+		}
+
+		for _, r := range beta.Coverage(string(p)) {
+			fmt.Printf("%s: %U %c\n", name, r, r)
+			if status == 0 {
+				status = 1
+			}
+		}
+	}
+
+	return status
+}
+
+// inputFiles returns the files named, with directories replaced by the files
+// with extension ext in them, possibly compressed, if recursive.
+func inputFiles(names []string, recursive bool, ext string) ([]string, error) {
+	var files []string
+
+	for _, name := range names {
+		fi, err := os.Stat(name)
+		if name == "-" || err != nil || !fi.IsDir() || !recursive {
+			files = append(files, name)
+			continue
+		}
+
+		err = filepath.Walk(name, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			base := strings.TrimSuffix(path, compression(path))
+			if fi.Mode().IsRegular() && filepath.Ext(base) == ext {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// converter converts files with the settings of the command line.
+type converter struct {
+	out       *output
+	opts      beta.Options
+	detect    bool // Detect the dialect of each file
+	errors    int  // Invalid Betacode recovered from, for the manifest
+	bidi      bool // Isolate right-to-left text
+	hyphenate bool // Put soft hyphens at hyphenation points
+	warn      bool // Report warnings
+}
+
+// file converts the named file and passes each converted line to emit. It
+// returns the exit status.
+func (c *converter) file(name string, emit func(line int, text string) error) int {
+	r, err := openInput(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+	defer r.Close()
+
+	return c.stream(name, r, emit)
+}
+
+// inPlace replaces the named file or archive by its conversion, compressed
+// like the original, and returns the exit status.
+func (c *converter) inPlace(name string) int {
+	fi, err := os.Stat(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".beta")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+	defer os.Remove(tmp.Name())
+
+	zw, err := compressOutput(tmp, compression(name))
+	if err != nil {
+		tmp.Close()
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+
+	var status int
+	if archiveFormat(name) != "" {
+		status = c.archive(name, zw)
+	} else {
+		status = c.file(name, func(line int, text string) error {
+			_, err := io.WriteString(zw, text)
+			return err
+		})
+	}
+
+	err = zw.Close()
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	// Invalid Betacode is skipped, so the conversion would lose input.
+	if err == nil && status == 0 {
+		if err = os.Chmod(tmp.Name(), fi.Mode()); err == nil {
+			err = os.Rename(tmp.Name(), name)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "beta:", err)
+		return 2
+	}
+
+	return status
+}
+
+// stream converts r line by line and passes each converted line to emit.
+// Invalid Betacode is reported and skipped. It returns the exit status.
+func (c *converter) stream(name string, r io.Reader, emit func(line int, text string) error) int {
+	status := 0
+	reader := bufio.NewReader(r)
+	var buf lineBuffer
+	w := beta.NewWriter(&buf)
+	w.Options = c.opts
+	if c.detect {
+		sample, _ := reader.Peek(4096)
+		w.Dialect = beta.DetectDialect(sample)
+	}
+
+	// Diagnostics get the word they are about from the line being
+	// converted, which starts at offset start.
+	var s string
+	start := 0
+	context := func(d beta.Diagnostic) beta.Diagnostic {
+		if i := d.Pos.Offset - start; i >= 0 && i <= len(s) {
+			d.Context, d.Greek = beta.WordContext(s, i, w.Options)
+		}
+		return d
+	}
+	if c.warn {
+		w.Warn = func(d beta.Diagnostic) {
+			c.out.diagnostic(name, context(d))
+		}
+	}
+	if w.Recovery == beta.RecoverError {
+		w.Handler = func(err error, pos beta.Position, source string) beta.Action {
+			status = 1
+			var serr *beta.SyntaxError
+			errors.As(err, &serr)
+			c.out.diagnostic(name, context(beta.Diagnostic{Pos: pos, Word: source, Msg: serr.Msg, Severity: beta.Error}))
+			return beta.Skip
+		}
+	}
+
+	for line := 1; ; line, start = line+1, start+len(s) {
+		var rerr error
+		s, rerr = reader.ReadString('\n')
+		if s == "" && rerr != nil {
+			if rerr != io.EOF {
+				fmt.Fprintf(os.Stderr, "beta: %s: %v\n", name, rerr)
+				return 2
+			}
+			break
+		}
 
-	for scanner.Scan() {
-		s := scanner.Text()
-		fmt.Fprintln(w, s)
-		w.Flush()
+		w.WriteString(s)
+		if err := w.Flush(); err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", err)
+			return 2
+		}
+
+		text := buf.take()
+		if c.hyphenate {
+			text = beta.Hyphenate(text)
+		}
+		if c.bidi {
+			text = beta.IsolateBidi(text)
+		}
+		if err := emit(line, text); err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", err)
+			return 2
+		}
+		if rerr != nil {
+			break
+		}
 	}
+
+	c.errors += w.Stats().Errors
+	return status
+}
+
+// lineBuffer collects the output of one line.
+type lineBuffer struct {
+	b []byte
+}
+
+func (lb *lineBuffer) Write(p []byte) (int, error) {
+	lb.b = append(lb.b, p...)
+	return len(p), nil
+}
+
+func (lb *lineBuffer) take() string {
+	s := string(lb.b)
+	lb.b = lb.b[:0]
+	return s
 }
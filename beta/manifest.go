@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// A manifest lists the files converted by a run, for pipelines that verify
+// and track the conversions.
+type manifest struct {
+	name  string
+	Files []manifestEntry `json:"files"`
+}
+
+// A manifestEntry records the conversion of an input file. The size and
+// hash of the output are those of the file written with -i, and otherwise
+// of its part of standard output, before compression.
+type manifestEntry struct {
+	Input       string `json:"input"`
+	Output      string `json:"output,omitempty"` // - for standard output; none if failed
+	InputBytes  int64  `json:"input_bytes"`
+	OutputBytes int64  `json:"output_bytes"`
+	Errors      int    `json:"errors"`            // Invalid Betacode reported or copied
+	Status      int    `json:"status"`            // Exit status of the file
+	SHA256      string `json:"sha256,omitempty"`  // Of the output
+	Skipped     bool   `json:"skipped,omitempty"` // Up to date according to the cache
+	Error       string `json:"error,omitempty"`   // Why the file was not replaced with -i
+}
+
+// add records the conversion e. Its output size and hash are those of out
+// or, if out is nil, of the output file.
+func (m *manifest) add(e manifestEntry, out *hashWriter) error {
+	if out == nil {
+		fi, err := os.Stat(e.Output)
+		if err != nil {
+			return err
+		}
+		e.OutputBytes = fi.Size()
+		if e.SHA256, err = hashFile(e.Output); err != nil {
+			return err
+		}
+	} else {
+		e.OutputBytes = out.n
+		e.SHA256 = hex.EncodeToString(out.h.Sum(nil))
+	}
+
+	m.Files = append(m.Files, e)
+	return nil
+}
+
+// addFailed records the failed conversion e, which left no output.
+func (m *manifest) addFailed(e manifestEntry) {
+	e.Output, e.OutputBytes, e.SHA256 = "", 0, ""
+	m.Files = append(m.Files, e)
+}
+
+// fileSize returns the size of the named file, or 0 if unknown, as for
+// standard input.
+func fileSize(name string) int64 {
+	fi, err := os.Stat(name)
+	if name == "-" || err != nil {
+		return 0
+	}
+	return fi.Size()
+}
+
+// save writes the manifest to its file.
+func (m *manifest) save() error {
+	if m.Files == nil {
+		m.Files = []manifestEntry{}
+	}
+	p, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(m.name, append(p, '\n'), 0666)
+}
+
+// A hashWriter writes to w and keeps the count and SHA-256 of the bytes
+// written.
+type hashWriter struct {
+	w io.Writer
+	h hash.Hash
+	n int64
+}
+
+func newHashWriter(w io.Writer) *hashWriter {
+	return &hashWriter{w: w, h: sha256.New()}
+}
+
+func (hw *hashWriter) Write(p []byte) (int, error) {
+	n, err := hw.w.Write(p)
+	hw.h.Write(p[:n])
+	hw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifestFailed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	good := filepath.Join(dir, "good.beta")
+	bad := filepath.Join(dir, "bad.beta")
+	missing := filepath.Join(dir, "missing.beta")
+	ioutil.WriteFile(good, []byte("lo/gos\n"), 0666)
+	ioutil.WriteFile(bad, []byte("k/ lo/gos\n"), 0666)
+
+	name := filepath.Join(dir, "manifest.json")
+	convert([]string{"-level", "standard", "-i", "-manifest", name, good, bad, missing})
+
+	p, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m manifest
+	if err := json.Unmarshal(p, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.Files) != 3 {
+		t.Fatalf("expected 3 files, got %+v", m.Files)
+	}
+	if e := m.Files[0]; e.Input != good || e.Output != good || e.Status != 0 || e.SHA256 == "" {
+		t.Errorf("expected %s to be converted, got %+v", good, e)
+	}
+	for _, e := range m.Files[1:] {
+		if e.Output != "" || e.SHA256 != "" || e.Status == 0 || e.Error == "" {
+			t.Errorf("expected %s to have failed without output, got %+v", e.Input, e)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/okitec/beta"
+)
+
+// output prints results and diagnostics as text or JSON lines.
+type output struct {
+	json bool
+	enc  *json.Encoder
+
+	// Warnings go to stderr too, as in a conversion, whose output they
+	// are not.
+	warnStderr bool
+}
+
+func newOutput(format string) *output {
+	switch format {
+	case "text":
+		return &output{}
+	case "json":
+		return &output{json: true, enc: json.NewEncoder(os.Stdout)}
+	}
+
+	fmt.Fprintln(os.Stderr, "beta: unknown format", format)
+	os.Exit(2)
+	return nil
+}
+
+// diagnostic prints a diagnostic about file. As text, it goes to stderr
+// unless it is a warning of proof, which is its output.
+func (o *output) diagnostic(file string, d beta.Diagnostic) {
+	if o.json {
+		o.enc.Encode(struct {
+			File     string `json:"file"`
+			Offset   int    `json:"offset"`
+			Line     int    `json:"line"`
+			Col      int    `json:"col"`
+			Message  string `json:"message"`
+			Severity string `json:"severity"`
+			Word     string `json:"word,omitempty"`
+			Context  string `json:"context,omitempty"`
+			Greek    string `json:"greek,omitempty"`
+		}{file, d.Pos.Offset, d.Pos.Line, d.Pos.Col, d.Msg, d.Severity.String(), d.Word, d.Context, d.Greek})
+		return
+	}
+
+	msg := fmt.Sprintf("%s:%s: %s", file, d.Pos, d.Msg)
+	switch {
+	case d.Context != "":
+		msg += fmt.Sprintf(": %s in %s (%s...)", d.Word, d.Context, d.Greek)
+	case d.Word != "":
+		msg += fmt.Sprintf(": %s (%s)", d.Word, greek(d.Word))
+	}
+	if d.Severity == beta.Warning && !o.warnStderr {
+		fmt.Println(msg)
+	} else {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+}
+
+// result prints the converted text of a line of file to w.
+func (o *output) result(w io.Writer, file string, line int, text string) error {
+	if o.json {
+		return json.NewEncoder(w).Encode(struct {
+			File string `json:"file"`
+			Line int    `json:"line"`
+			Text string `json:"text"`
+		}{file, line, text})
+	}
+
+	_, err := io.WriteString(w, text)
+	return err
+}
+
+// greek converts a Betacode word for display, as far as possible.
+func greek(word string) string {
+	var sb strings.Builder
+	w := beta.NewWriter(&sb)
+	w.Write([]byte(word))
+	w.Flush()
+	return sb.String()
+}
+
+// readFile reads the named file, or standard input for "-", and
+// decompresses it according to its extension.
+func readFile(name string) ([]byte, error) {
+	r, err := openInput(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/okitec/beta"
+)
+
+// proof prints the diagnostics of beta.Proof for each file and returns the
+// exit status.
+func proof(args []string) int {
+	flags := flag.NewFlagSet("beta proof", flag.ExitOnError)
+	format := flags.String("format", "text", "output `format`: text or json")
+	flags.Parse(args)
+	out := newOutput(*format)
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	status := 0
+	for _, name := range files {
+		src, err := readFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", err)
+			status = 2
+			continue
+		}
+
+		for _, d := range beta.Proof(string(src)) {
+			out.diagnostic(name, d)
+			if status == 0 {
+				status = 1
+			}
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/okitec/beta"
+)
+
+// The reference corpus of selftest: Iliad 1.1-10 in Greek and in each
+// dialect. The Greek is in NFC, with the diacritics of the Betacode
+// symbols in canonical order, as Options.Canonical writes them.
+const selfTestGreek = `μῆνιν ἄειδε θεὰ Πηληϊάδεω Ἀχιλῆος
+οὐλομένην, ἣ μυρί' Ἀχαιοῖς ἄλγε' ἔθηκε,
+πολλὰς δ' ἰφθίμους ψυχὰς Ἄϊδι προΐαψεν
+ἡρώων, αὐτοὺς δὲ ἑλώρια τεῦχε κύνεσσιν
+οἰωνοῖσί τε πᾶσι, Διὸς δ' ἐτελείετο βουλή,
+ἐξ οὗ δὴ τὰ πρῶτα διαστήτην ἐρίσαντε
+Ἀτρεΐδης τε ἄναξ ἀνδρῶν καὶ δῖος Ἀχιλλεύς.
+τίς τ' ἄρ σφωε θεῶν ἔριδι ξυνέηκε μάχεσθαι;
+Λητοῦς καὶ Διὸς υἱός· ὃ γὰρ βασιλῆϊ χολωθεὶς
+νοῦσον ἀνὰ στρατὸν ὄρσε κακήν, ὀλέκοντο δὲ λαοί,
+`
+
+// The corpus in each dialect, with s2 for final sigma where it is written
+// with asterisks, as the encoder writes it.
+var selfTests = []struct {
+	dialect beta.Dialect
+	src     string
+}{
+	{beta.DialectTypeGreek, `mh=nin a)/eide qea\ Phlhi+a/dew A)xilh=os
+ou)lome/nhn, h(\ muri/' A)xaioi=s a)/lge' e)/qhke,
+polla\s d' i)fqi/mous yuxa\s A)/i+di proi/+ayen
+h(rw/wn, au)tou\s de\ e(lw/ria teu=xe ku/nessin
+oi)wnoi=si/ te pa=si, Dio\s d' e)telei/eto boulh/,
+e)c ou(= dh\ ta\ prw=ta diasth/thn e)ri/sante
+A)trei/+dhs te a)/nac a)ndrw=n kai\ di=os A)xilleu/s.
+ti/s t' a)/r sfwe qew=n e)/ridi cune/hke ma/xesqai;
+Lhtou=s kai\ Dio\s ui(o/s· o(\ ga\r basilh=i+ xolwqei\s
+nou=son a)na\ strato\n o)/rse kakh/n, o)le/konto de\ laoi/,
+`},
+	{beta.DialectStandard, `mh=nin a)/eide qea\ *phlhi+a/dew *)axilh=os2
+ou)lome/nhn, h(\ muri/' *)axaioi=s2 a)/lge' e)/qhke,
+polla\s2 d' i)fqi/mous2 yuxa\s2 *)/ai+di proi/+ayen
+h(rw/wn, au)tou\s2 de\ e(lw/ria teu=xe ku/nessin
+oi)wnoi=si/ te pa=si, *dio\s2 d' e)telei/eto boulh/,
+e)c ou(= dh\ ta\ prw=ta diasth/thn e)ri/sante
+*)atrei/+dhs2 te a)/nac a)ndrw=n kai\ di=os2 *)axilleu/s2.
+ti/s2 t' a)/r sfwe qew=n e)/ridi cune/hke ma/xesqai;
+*lhtou=s2 kai\ *dio\s2 ui(o/s2· o(\ ga\r basilh=i+ xolwqei\s2
+nou=son a)na\ strato\n o)/rse kakh/n, o)le/konto de\ laoi/,
+`},
+	{beta.DialectTLG, `MH=NIN A)/EIDE QEA\ *PHLHI+A/DEW *)AXILH=OS2
+OU)LOME/NHN, H(\ MURI/' *)AXAIOI=S2 A)/LGE' E)/QHKE,
+POLLA\S2 D' I)FQI/MOUS2 YUXA\S2 *)/AI+DI PROI/+AYEN
+H(RW/WN, AU)TOU\S2 DE\ E(LW/RIA TEU=XE KU/NESSIN
+OI)WNOI=SI/ TE PA=SI, *DIO\S2 D' E)TELEI/ETO BOULH/,
+E)C OU(= DH\ TA\ PRW=TA DIASTH/THN E)RI/SANTE
+*)ATREI/+DHS2 TE A)/NAC A)NDRW=N KAI\ DI=OS2 *)AXILLEU/S2.
+TI/S2 T' A)/R SFWE QEW=N E)/RIDI CUNE/HKE MA/XESQAI;
+*LHTOU=S2 KAI\ *DIO\S2 UI(O/S2· O(\ GA\R BASILH=I+ XOLWQEI\S2
+NOU=SON A)NA\ STRATO\N O)/RSE KAKH/N, O)LE/KONTO DE\ LAOI/,
+`},
+	{beta.DialectKeyboard, `mh=nin a)/eide uea\ Phlhi+a/dev A)xilh=os
+oy)lome/nhn, h(\ myri/' A)xaioi=s a)/lge' e)/uhke,
+polla\s d' i)fui/moys cyxa\s A)/i+di proi/+acen
+h(rv/vn, ay)toy\s de\ e(lv/ria tey=xe ky/nessin
+oi)vnoi=si/ te pa=si, Dio\s d' e)telei/eto boylh/,
+e)j oy(= dh\ ta\ prv=ta diasth/thn e)ri/sante
+A)trei/+dhs te a)/naj a)ndrv=n kai\ di=os A)xilley/s.
+ti/s t' a)/r sfve uev=n e)/ridi jyne/hke ma/xesuai;
+Lhtoy=s kai\ Dio\s yi(o/s· o(\ ga\r basilh=i+ xolvuei\s
+noy=son a)na\ strato\n o)/rse kakh/n, o)le/konto de\ laoi/,
+`},
+}
+
+// selftest converts the reference corpus in each dialect to Greek and back
+// and compares the results with the expected ones, to check that the
+// binary converts as it should on this platform. It returns the exit
+// status.
+func selftest(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "usage: beta selftest")
+		return 2
+	}
+
+	status := 0
+	for _, tt := range selfTests {
+		opts := beta.Options{Dialect: tt.dialect, Digits: beta.DigitsCodes, Canonical: true, VerifyNFC: true}
+		out, err := beta.ConvertAppend(nil, []byte(tt.src), opts)
+		if err == nil {
+			err = compareLines(string(out), selfTestGreek)
+		}
+		if err != nil {
+			fmt.Printf("FAIL\t%s to Greek: %v\n", tt.dialect, err)
+			status = 1
+		} else {
+			fmt.Printf("ok\t%s to Greek\n", tt.dialect)
+		}
+
+		src, err := beta.FromGreekDialect(selfTestGreek, tt.dialect)
+		if err == nil {
+			err = compareLines(src, tt.src)
+		}
+		if err != nil {
+			fmt.Printf("FAIL\tGreek to %s: %v\n", tt.dialect, err)
+			status = 1
+		} else {
+			fmt.Printf("ok\tGreek to %s\n", tt.dialect)
+		}
+	}
+
+	return status
+}
+
+// compareLines returns an error describing the first line in which got
+// differs from want, or nil if they are the same.
+func compareLines(got, want string) error {
+	g, w := strings.SplitAfter(got, "\n"), strings.SplitAfter(want, "\n")
+	for i := 0; i < len(g) || i < len(w); i++ {
+		var gl, wl string
+		if i < len(g) {
+			gl = g[i]
+		}
+		if i < len(w) {
+			wl = w[i]
+		}
+		if gl != wl {
+			return fmt.Errorf("line %d: got %+q, want %+q", i+1, gl, wl)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/okitec/beta"
+)
+
+// symJSON is a beta.Sym with its diacritics as Betacode strings.
+type symJSON struct {
+	Base      string `json:"base"`
+	Accent    string `json:"accent,omitempty"`
+	Breathing string `json:"breathing,omitempty"`
+	Iota      bool   `json:"iota,omitempty"`
+	Trema     bool   `json:"trema,omitempty"`
+	Greek     string `json:"greek"`
+}
+
+// wordJSON is a beta.Word with its position and converted form.
+type wordJSON struct {
+	File   string    `json:"file"`
+	Word   string    `json:"word"`
+	Offset int       `json:"offset"`
+	Line   int       `json:"line"`
+	Col    int       `json:"col"`
+	Greek  string    `json:"greek"`
+	Syms   []symJSON `json:"syms"`
+}
+
+// runeString returns r as a string, or "" for 0.
+func runeString(r rune) string {
+	if r == 0 {
+		return ""
+	}
+	return string(r)
+}
+
+// tokens prints the parsed words of each file as JSON lines and returns the
+// exit status.
+func tokens(args []string) int {
+	flags := flag.NewFlagSet("beta tokens", flag.ExitOnError)
+	flags.Parse(args)
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	out := newOutput("json")
+	status := 0
+
+	for _, name := range files {
+		src, err := readFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "beta:", err)
+			status = 2
+			continue
+		}
+
+		words, err := beta.Words(string(src))
+		for _, w := range words {
+			wj := wordJSON{
+				File:   name,
+				Word:   w.Source,
+				Offset: w.Pos.Offset,
+				Line:   w.Pos.Line,
+				Col:    w.Pos.Col,
+				Greek:  w.Greek(),
+			}
+			for _, sym := range w.Syms {
+				wj.Syms = append(wj.Syms, symJSON{
+					Base:      string(sym.Base),
+					Accent:    runeString(sym.Accent),
+					Breathing: runeString(sym.Spiritus),
+					Iota:      sym.Iota,
+					Trema:     sym.Trema,
+					Greek:     sym.PrecombinedString(),
+				})
+			}
+			enc.Encode(wj)
+		}
+
+		if serr, ok := err.(*beta.SyntaxError); ok {
+			d := beta.Diagnostic{Pos: serr.Pos, Msg: serr.Msg, Severity: beta.Error}
+			d.Word, d.Greek = beta.WordContext(string(src), serr.Pos.Offset, beta.Options{})
+			out.diagnostic(name, d)
+			status = 1
+		}
+	}
+
+	return status
+}
@@ -27,3 +27,18 @@ func TestBeta(t *testing.T) {
 		t.Error("expected 'A)=', got '", s, "'")
 	}
 }
+
+func TestForms(t *testing.T) {
+	var sym Sym
+	for _, r := range "a)/|" {
+		sym.Add(r)
+	}
+
+	nfc, combining := sym.Forms()
+	if string(nfc) != "\u1F84" {
+		t.Errorf("expected %q, got %q", "\u1F84", nfc)
+	}
+	if string(combining) != "\u03B1\u0313\u0301\u0345" {
+		t.Errorf("expected %q, got %q", "\u03B1\u0313\u0301\u0345", combining)
+	}
+}
@@ -0,0 +1,58 @@
+package beta
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+// Bidi controls that enclose right-to-left text
+const (
+	rli = '\u2067' // Right-to-left isolate
+	pdi = '\u2069' // Pop directional isolate
+)
+
+// IsolateBidi returns text with each run of right-to-left characters, like
+// Hebrew in literal regions, enclosed in the bidi controls RLI and PDI, so
+// that plain-text displays don't reorder the Greek around it. A run extends
+// from a right-to-left letter to the last one before the next left-to-right
+// letter or line break, with the spaces, digits and punctuation between
+// them. Text without right-to-left letters is returned unchanged.
+func IsolateBidi(text string) string {
+	var sb strings.Builder
+	done := 0   // Offset up to which text is written
+	start := -1 // Start of the current run, -1 if none
+	end := 0    // End of the last right-to-left letter of the run
+
+	closeRun := func() {
+		if start < 0 {
+			return
+		}
+		sb.WriteString(text[done:start])
+		sb.WriteRune(rli)
+		sb.WriteString(text[start:end])
+		sb.WriteRune(pdi)
+		done, start = end, -1
+	}
+
+	for i, r := range text {
+		p, _ := bidi.LookupRune(r)
+		switch p.Class() {
+		case bidi.R, bidi.AL:
+			if start < 0 {
+				start = i
+			}
+			end = i + utf8.RuneLen(r)
+		case bidi.L, bidi.B:
+			closeRun()
+		}
+	}
+	closeRun()
+
+	if done == 0 {
+		return text
+	}
+	sb.WriteString(text[done:])
+	return sb.String()
+}
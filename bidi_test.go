@@ -0,0 +1,22 @@
+package beta
+
+import "testing"
+
+func TestIsolateBidi(t *testing.T) {
+	tests := []struct {
+		text, isolated string
+	}{
+		{"\u03B8\u03B5\u03AC", "\u03B8\u03B5\u03AC"},
+		{
+			"\u03B8\u03B5\u03AC \u05E9\u05DC\u05D5\u05DD 12 \u05E2\u05D5\u05DC\u05DD, \u03BB\u03CC\u03B3\u03BF\u03C2",
+			"\u03B8\u03B5\u03AC \u2067\u05E9\u05DC\u05D5\u05DD 12 \u05E2\u05D5\u05DC\u05DD\u2069, \u03BB\u03CC\u03B3\u03BF\u03C2",
+		},
+		{"\u05E9\u05DC\u05D5\u05DD,\n\u05E9\u05DC\u05D5\u05DD", "\u2067\u05E9\u05DC\u05D5\u05DD\u2069,\n\u2067\u05E9\u05DC\u05D5\u05DD\u2069"},
+	}
+
+	for _, tt := range tests {
+		if s := IsolateBidi(tt.text); s != tt.isolated {
+			t.Errorf("%q: expected %q, got %q", tt.text, tt.isolated, s)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package beta
+
+import "errors"
+
+// A SymBuilder constructs a Sym from a base letter and diacritics given by
+// method calls, for code that generates Greek rather than parsing Betacode:
+//
+//	sym, err := NewSym('a').Smooth().Acute().IotaSub().Sym()
+//
+// The methods check the same rules as LevelStandard. The first error is
+// kept, and later methods do nothing.
+type SymBuilder struct {
+	sym Sym
+	err error
+}
+
+// NewSym starts a Sym with the base Betacode letter base, like a or H.
+func NewSym(base rune) *SymBuilder {
+	b := new(SymBuilder)
+	if base < 'A' || base > 'Z' && base < 'a' || base > 'z' {
+		b.err = errors.New("invalid base letter " + string(base))
+		return b
+	}
+	b.sym.Add(base)
+	return b
+}
+
+// add adds the Betacode diacritic r to the Sym.
+func (b *SymBuilder) add(r rune) *SymBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	old := b.sym
+	switch {
+	case !b.sym.Add(r):
+		b.err = b.sym.Err()
+	case old.Accent != 0 && (r == '/' || r == '\\' || r == '='):
+		b.err = errors.New("can't put second accent on symbol")
+	case old.Spiritus != 0 && (r == '(' || r == ')'):
+		b.err = errors.New("can't put second breathing on symbol")
+	default:
+		b.err = strictErr(b.sym)
+	}
+	return b
+}
+
+// Acute adds an acute accent.
+func (b *SymBuilder) Acute() *SymBuilder { return b.add('/') }
+
+// Grave adds a grave accent.
+func (b *SymBuilder) Grave() *SymBuilder { return b.add('\\') }
+
+// Circumflex adds a circumflex.
+func (b *SymBuilder) Circumflex() *SymBuilder { return b.add('=') }
+
+// Smooth adds a smooth breathing.
+func (b *SymBuilder) Smooth() *SymBuilder { return b.add(')') }
+
+// Rough adds a rough breathing.
+func (b *SymBuilder) Rough() *SymBuilder { return b.add('(') }
+
+// IotaSub adds an iota subscript.
+func (b *SymBuilder) IotaSub() *SymBuilder { return b.add('|') }
+
+// Diaeresis adds a diaeresis.
+func (b *SymBuilder) Diaeresis() *SymBuilder { return b.add('+') }
+
+// Sym returns the Sym built, or the first error.
+func (b *SymBuilder) Sym() (Sym, error) {
+	if b.err != nil {
+		return Sym{}, b.err
+	}
+	return b.sym, nil
+}
@@ -0,0 +1,34 @@
+package beta
+
+import "testing"
+
+func TestSymBuilder(t *testing.T) {
+	sym, err := NewSym('a').Smooth().Acute().IotaSub().Sym()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sym.String() != "a)/|" {
+		t.Error("expected 'a)/|', got '" + sym.String() + "'")
+	}
+
+	sym, err = NewSym('I').Rough().Circumflex().Sym()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := sym.PrecombinedString(); s != "\u1F3F" {
+		t.Errorf("expected %q, got %q", "\u1F3F", s)
+	}
+
+	for _, b := range []*SymBuilder{
+		NewSym('k').Acute(),
+		NewSym('e').Circumflex(),
+		NewSym('a').Acute().Grave(),
+		NewSym('o').IotaSub(),
+		NewSym('1'),
+		NewSym('a').Rough().Smooth().Acute(),
+	} {
+		if _, err := b.Sym(); err == nil {
+			t.Errorf("expected error for %q", b.sym.String())
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package beta
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Grapheme cluster break properties of UAX #29, as far as they are needed.
+type gcb int
+
+const (
+	gcbOther gcb = iota
+	gcbCR
+	gcbLF
+	gcbControl
+	gcbExtend
+	gcbZWJ
+	gcbSpacingMark
+	gcbRI // Regional indicator
+	gcbL  // Hangul leading jamo
+	gcbV  // Hangul vowel jamo
+	gcbT  // Hangul trailing jamo
+	gcbLV
+	gcbLVT
+)
+
+func breakProp(r rune) gcb {
+	switch {
+	case r == '\r':
+		return gcbCR
+	case r == '\n':
+		return gcbLF
+	case r == 0x200D:
+		return gcbZWJ
+	case r == 0x200C, unicode.In(r, unicode.Mn, unicode.Me), r >= 0x1F3FB && r <= 0x1F3FF:
+		return gcbExtend
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return gcbControl
+	case unicode.Is(unicode.Mc, r):
+		return gcbSpacingMark
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return gcbRI
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return gcbL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return gcbV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return gcbT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return gcbLV
+		}
+		return gcbLVT
+	}
+	return gcbOther
+}
+
+// noBreak reports whether there is no cluster boundary between runes with the
+// properties a and b. ris is the number of regional indicators before b.
+func noBreak(a, b gcb, ris int) bool {
+	switch {
+	case a == gcbCR && b == gcbLF: // GB3
+		return true
+	case a == gcbCR || a == gcbLF || a == gcbControl: // GB4
+		return false
+	case b == gcbCR || b == gcbLF || b == gcbControl: // GB5
+		return false
+	case a == gcbL && (b == gcbL || b == gcbV || b == gcbLV || b == gcbLVT): // GB6
+		return true
+	case (a == gcbLV || a == gcbV) && (b == gcbV || b == gcbT): // GB7
+		return true
+	case (a == gcbLVT || a == gcbT) && b == gcbT: // GB8
+		return true
+	case b == gcbExtend || b == gcbZWJ || b == gcbSpacingMark: // GB9, GB9a
+		return true
+	case a == gcbRI && b == gcbRI: // GB12, GB13
+		return ris%2 == 1
+	}
+	return false // GB999
+}
+
+// clusterLen returns the length in bytes of the first grapheme cluster in s.
+func clusterLen(s string) int {
+	return clusterEnd(len(s), func(i int) (rune, int) {
+		return utf8.DecodeRuneInString(s[i:])
+	})
+}
+
+// clusterEnd returns the end of the first grapheme cluster in an input of
+// length n, whose runes are returned by decode.
+func clusterEnd(n int, decode func(i int) (rune, int)) int {
+	if n == 0 {
+		return 0
+	}
+
+	r, i := decode(0)
+	prev := breakProp(r)
+	ris := 0
+	if prev == gcbRI {
+		ris = 1
+	}
+
+	for i < n {
+		r, size := decode(i)
+		p := breakProp(r)
+		if !noBreak(prev, p, ris) {
+			break
+		}
+		if p == gcbRI {
+			ris++
+		}
+		prev = p
+		i += size
+	}
+
+	return i
+}
+
+// ScanClusters is a split function for a bufio.Scanner that returns each
+// extended grapheme cluster (a user-perceived character, like a base letter
+// with all its combining marks) as a token. It follows the rules of Unicode
+// Standard Annex #29 except those for emoji ZWJ sequences and prepended
+// concatenation marks.
+func ScanClusters(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	n := clusterEnd(len(data), func(i int) (rune, int) {
+		return utf8.DecodeRune(data[i:])
+	})
+
+	// The cluster may continue with more data, or with a rune that is only
+	// partly there.
+	if !atEOF && (n == len(data) || !utf8.FullRune(data[n:])) {
+		return 0, nil, nil
+	}
+
+	return n, data[:n], nil
+}
+
+// CountClusters returns the number of grapheme clusters in s, the number of
+// characters a reader sees, however they are normalized.
+func CountClusters(s string) int {
+	n := 0
+	for len(s) > 0 {
+		s = s[clusterLen(s):]
+		n++
+	}
+	return n
+}
+
+// TruncateClusters returns the first n grapheme clusters of s, so that no
+// letter loses its combining diacritics, or s if it has no more.
+func TruncateClusters(s string, n int) string {
+	i := 0
+	for ; n > 0 && i < len(s); n-- {
+		i += clusterLen(s[i:])
+	}
+	return s[:i]
+}
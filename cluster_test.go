@@ -0,0 +1,56 @@
+package beta
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestScanClusters(t *testing.T) {
+	const src = "ἄϊ \r\nx\u0301\U0001F1E9\U0001F1EA\U0001F1EB"
+
+	ref := []string{"ἄ", "ϊ", " ", "\r\n", "x\u0301", "\U0001F1E9\U0001F1EA", "\U0001F1EB"}
+
+	sc := bufio.NewScanner(strings.NewReader(src))
+	sc.Split(ScanClusters)
+	var got []string
+	for sc.Scan() {
+		got = append(got, sc.Text())
+	}
+
+	if len(got) != len(ref) {
+		t.Fatalf("expected %q, got %q", ref, got)
+	}
+	for i := range ref {
+		if got[i] != ref[i] {
+			t.Errorf("expected %q, got %q", ref[i], got[i])
+		}
+	}
+}
+
+func TestTruncateClusters(t *testing.T) {
+	const s = "\u03B1\u0313\u0301ειδε\u0345 \u1F04"
+
+	if n := CountClusters(s); n != 7 {
+		t.Errorf("expected 7 clusters, got %d", n)
+	}
+	if n := CountClusters(""); n != 0 {
+		t.Errorf("expected 0 clusters, got %d", n)
+	}
+
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, ""},
+		{1, "\u03B1\u0313\u0301"},
+		{5, "\u03B1\u0313\u0301ειδε\u0345"},
+		{7, s},
+		{10, s},
+	}
+	for _, tt := range tests {
+		if got := TruncateClusters(s, tt.n); got != tt.want {
+			t.Errorf("%d: expected %q, got %q", tt.n, tt.want, got)
+		}
+	}
+}
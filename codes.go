@@ -0,0 +1,68 @@
+package beta
+
+import "strings"
+
+// Kinds of multiCode
+const (
+	codeNone  = iota
+	codeSigma // s1, s2 or s3
+	codeSign  // A character other than a letter, like [1 for (
+)
+
+// A multiCode is a code of Standard Betacode that takes more than one rune,
+// or a sign like % whose code may go on with digits.
+type multiCode struct {
+	kind int
+	n    int  // Runes of the code
+	r    rune // The digit of a sigma or the character of a sign
+}
+
+// Characters of the signs, by code
+var signCodes = map[string]rune{
+	"[": '[', "[1": '(', "[2": '<', "[3": '{', "[4": '\u27E6',
+	"]": ']', "]1": ')', "]2": '>', "]3": '}', "]4": '\u27E7',
+
+	`"`: '"', `"1`: '\u201E', `"2`: '\u201C', `"3`: '\u201D',
+
+	"%": '\u2020', "%1": '?', "%2": '*', "%3": '/', "%4": '!', "%5": '|',
+	"%6": '=', "%7": '+', "%8": '%', "%9": '&', "%10": ':', "%13": '\u2021',
+	"%14": '\u00A7',
+
+	"#": '\u0374', "#1": '\u03DF', "#2": '\u03DB', "#3": '\u03D9', "#5": '\u03E1',
+}
+
+// Runes that start a sign
+const signStarts = `[]"%#`
+
+// lexCode returns the multiCode at the start of an input of n runes, which
+// are returned by at, or tells to wait for more input. Unless atEnd, a code
+// is only complete with the rune after it, since more digits might follow.
+// The kind of the multiCode is codeNone if there is none.
+func lexCode(n int, at func(i int) rune, atEnd bool) (c multiCode, wait bool) {
+	first := at(0)
+	switch {
+	case first == 's' || first == 'S':
+		if n == 1 {
+			return c, !atEnd
+		}
+		if r := at(1); '1' <= r && r <= '3' {
+			return multiCode{codeSigma, 2, r}, false
+		}
+
+	case strings.ContainsRune(signStarts, first):
+		var sb strings.Builder
+		sb.WriteRune(first)
+		i := 1
+		for ; i < n && '0' <= at(i) && at(i) <= '9'; i++ {
+			sb.WriteRune(at(i))
+		}
+		if i == n && !atEnd {
+			return c, true
+		}
+		if r, ok := signCodes[sb.String()]; ok {
+			return multiCode{codeSign, i, r}, false
+		}
+	}
+
+	return c, false
+}
@@ -0,0 +1,30 @@
+package beta
+
+import "testing"
+
+func TestLexCode(t *testing.T) {
+	tests := []struct {
+		src   string
+		atEnd bool
+		code  multiCode
+		wait  bool
+	}{
+		{"s1a", false, multiCode{codeSigma, 2, '1'}, false},
+		{"S3", true, multiCode{codeSigma, 2, '3'}, false},
+		{"s4", true, multiCode{}, false},
+		{"s", false, multiCode{}, true},
+		{"s", true, multiCode{}, false},
+		{"%10 ", false, multiCode{codeSign, 3, ':'}, false},
+		{"%1", false, multiCode{}, true},
+		{"%12 ", false, multiCode{}, false},
+		{"# ", false, multiCode{codeSign, 1, '\u0374'}, false},
+		{"a1", true, multiCode{}, false},
+	}
+
+	for _, tt := range tests {
+		c, wait := lexCode(len(tt.src), func(i int) rune { return rune(tt.src[i]) }, tt.atEnd)
+		if c != tt.code || wait != tt.wait {
+			t.Errorf("%q: expected %v, %v, got %v, %v", tt.src, tt.code, tt.wait, c, wait)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package beta
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Betacode for Greek letters and combining diacritics; the inverse of code.
+var greekCode = map[rune]rune{}
+
+func init() {
+	for b, g := range code {
+		// Capital sigma is S; J is only a TypeGreek convenience.
+		if b == 'J' {
+			continue
+		}
+		greekCode[g] = b
+	}
+}
+
+// ToGreek converts Betacode to precombined Greek. Unlike a Writer, it knows
+// where its input ends, so a sigma at the very end becomes final sigma.
+// On error, the output up to the error is returned.
+func ToGreek(betacode string) (string, error) {
+	var d Decoder
+	buf, err := d.convert(nil, betacode)
+	return string(buf), err
+}
+
+// ToGreekMap is like ToGreek, but also returns the Mappings of the output to
+// the input.
+func ToGreekMap(betacode string) (string, []Mapping, error) {
+	var maps []Mapping
+	d := Decoder{Options: Options{Map: func(m Mapping) {
+		maps = append(maps, m)
+	}}}
+
+	buf, err := d.convert(nil, betacode)
+	return string(buf), maps, err
+}
+
+// ConvertAll converts each item of Betacode to Greek like ToGreek, but with
+// opts. The results are in the order of the items. errs is nil if all items
+// were converted; otherwise errs[i] is the error of items[i], if any, and
+// greek[i] the output up to it.
+func ConvertAll(items []string, opts Options) (greek []string, errs []error) {
+	d := Decoder{Options: opts}
+	var buf []byte
+
+	greek = make([]string, len(items))
+	for i, item := range items {
+		var err error
+		buf, err = d.convert(buf[:0], item)
+		greek[i] = string(buf)
+		if err != nil {
+			if errs == nil {
+				errs = make([]error, len(items))
+			}
+			errs[i] = err
+		}
+		d.Reset()
+	}
+
+	return greek, errs
+}
+
+// Decoders for ConvertAppend
+var decoders = sync.Pool{New: func() interface{} { return new(Decoder) }}
+
+// ConvertAppend appends the conversion of the Betacode src with opts to dst
+// and returns the extended buffer; on error, up to the error. It only
+// allocates to grow dst, so with a dst of sufficient capacity, as from a
+// pool, converting costs no allocations.
+func ConvertAppend(dst, src []byte, opts Options) ([]byte, error) {
+	d := decoders.Get().(*Decoder)
+	d.Reset()
+	d.Options = opts
+
+	var err error
+	for len(src) > 0 {
+		r, size := utf8.DecodeRune(src)
+		src = src[size:]
+		if dst, err = d.pushSize(dst, r, size); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		dst, err = d.end(dst)
+	}
+
+	d.Options = Options{}
+	decoders.Put(d)
+	return dst, err
+}
+
+// DecodeBytes converts Betacode in src, which may be any bytes, to Greek
+// with opts. The output is valid UTF-8 even if src is not: invalid bytes
+// become U+FFFD, and their offsets are returned in bad. On error, the output
+// up to the error is returned.
+func DecodeBytes(src []byte, opts Options) (greek []byte, bad []int, err error) {
+	d := Decoder{Options: opts}
+
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRune(src[i:])
+		if r == utf8.RuneError && size == 1 {
+			bad = append(bad, i)
+		}
+		i += size
+		if greek, err = d.pushSize(greek, r, size); err != nil {
+			return greek, bad, err
+		}
+	}
+
+	greek, err = d.end(greek)
+	return greek, bad, err
+}
+
+// convert appends the conversion of the complete input betacode to dst.
+func (d *Decoder) convert(dst []byte, betacode string) ([]byte, error) {
+	var err error
+	for len(betacode) > 0 {
+		r, size := utf8.DecodeRuneInString(betacode)
+		betacode = betacode[size:]
+		if dst, err = d.pushSize(dst, r, size); err != nil {
+			return dst, err
+		}
+	}
+
+	return d.end(dst)
+}
+
+// FromGreek converts Greek text, precombined or with combining diacritics,
+// to TypeGreek Betacode. Characters that are neither Greek letters nor
+// diacritics are copied unchanged. Greek letters and combining marks that
+// have no Betacode equivalent are an error.
+//
+// The text is processed by grapheme cluster, so the combining marks of a
+// letter may come in any order, and any normalization gives the same
+// Betacode: tonos and oxia are both /, and the prosgegrammeni (U+1FBE), the
+// spacing iota adscript, is | like the combining U+0345.
+func FromGreek(greek string) (string, error) {
+	return FromGreekDialect(greek, DialectTypeGreek)
+}
+
+// FromGreekDialect is like FromGreek, but converts to the Betacode of
+// dialect. The Standard dialects write the sigmas as s1, s2 and s3, which
+// are read back only with Options.Digits DigitsCodes; see Encoder.Digits.
+func FromGreekDialect(greek string, dialect Dialect) (string, error) {
+	var sb strings.Builder
+
+	s := norm.NFD.String(strings.ReplaceAll(greek, prosgegrammeni, ypogegrammeni))
+	for len(s) > 0 {
+		n := clusterLen(s)
+		cluster := s[:n]
+		s = s[n:]
+
+		next, _ := utf8.DecodeRuneInString(s)
+		beta, err := encoding{dialect: dialect, codes: true}.encodeCluster(cluster, next)
+		if err != nil {
+			return sb.String(), err
+		}
+		sb.WriteString(beta)
+	}
+
+	return sb.String(), nil
+}
+
+// The spacing and the combining iota subscript or adscript
+const (
+	prosgegrammeni = "\u1FBE"
+	ypogegrammeni  = "\u0345"
+)
+
+// Coverage returns the characters of greek that FromGreek cannot convert
+// to Betacode, like sampi or a macron, in ascending order. Characters that
+// are neither Greek nor a diacritic of a Greek letter are copied by
+// FromGreek and are not returned.
+func Coverage(greek string) []rune {
+	seen := map[rune]bool{}
+
+	s := strings.ReplaceAll(greek, prosgegrammeni, ypogegrammeni)
+	for len(s) > 0 {
+		n := clusterLen(s)
+		cluster := s[:n]
+		s = s[n:]
+
+		base, _ := utf8.DecodeRuneInString(norm.NFD.String(cluster))
+		b, known := greekCode[base]
+		switch {
+		case base == '\u03F2' || base == '\u03F9' || known && unicode.IsLetter(b):
+			for _, r := range cluster {
+				if !encodable(r) {
+					seen[r] = true
+				}
+			}
+		case unicode.Is(unicode.Greek, base):
+			r, _ := utf8.DecodeRuneInString(cluster)
+			seen[r] = true
+		}
+	}
+
+	var runes []rune
+	for r := range seen {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return runes
+}
+
+// encodable reports whether r, a letter or diacritic of a Greek letter, has
+// Betacode.
+func encodable(r rune) bool {
+	if r == '\u03F2' || r == '\u03F9' {
+		return true
+	}
+	for _, d := range norm.NFD.String(string(r)) {
+		if _, ok := greekCode[d]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// BetaFor returns the Betacode spelling of a Greek letter with its
+// diacritics, precombined or not, in the form produced by Sym.String.
+// Final sigma is j, since s only becomes final at the end of a word.
+func BetaFor(cluster string) (string, error) {
+	s := norm.NFD.String(cluster)
+	if s == "" || clusterLen(s) != len(s) {
+		return "", fmt.Errorf("%q is not a single character", cluster)
+	}
+
+	sym, ok, err := clusterSym(s)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%q is not a Greek letter", cluster)
+	}
+	return sym.String(), nil
+}
+
+// clusterSym converts a grapheme cluster in NFD to a Sym. It returns false if
+// the cluster is not a Greek letter.
+func clusterSym(cluster string) (sym Sym, ok bool, err error) {
+	for i, r := range cluster {
+		b, known := greekCode[r]
+
+		if i == 0 {
+			if known && unicode.IsLetter(b) {
+				sym.Base = b
+				continue
+			}
+			if unicode.Is(unicode.Greek, r) {
+				return sym, false, fmt.Errorf("no Betacode for %U", r)
+			}
+			if known {
+				return sym, false, fmt.Errorf("diacritic %U without base letter", r)
+			}
+			return sym, false, nil
+		}
+
+		if !known {
+			return sym, false, fmt.Errorf("no Betacode for %U", r)
+		}
+		if !sym.Add(b) {
+			return sym, false, sym.Err()
+		}
+	}
+
+	return sym, sym.Base != 0, nil
+}
+
+// MustToGreek is like ToGreek but panics if the Betacode cannot be converted.
+// It simplifies the initialisation of variables holding known-good literals.
+func MustToGreek(betacode string) string {
+	s, err := ToGreek(betacode)
+	if err != nil {
+		panic(`beta: ToGreek(` + strconv.Quote(betacode) + `): ` + err.Error())
+	}
+	return s
+}
+
+// MustFromGreek is like FromGreek but panics if the Greek cannot be converted.
+// It simplifies the initialisation of variables holding known-good literals.
+func MustFromGreek(greek string) string {
+	s, err := FromGreek(greek)
+	if err != nil {
+		panic(`beta: FromGreek(` + strconv.Quote(greek) + `): ` + err.Error())
+	}
+	return s
+}
@@ -0,0 +1,225 @@
+package beta
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestToGreek(t *testing.T) {
+	const ref = `Μῆνιν ἄειδε, θεά, Πηληϊάδεω Ἀχιλῆος`
+
+	s, err := ToGreek("Mh=nin a)/eide, qea/, Phlhi+a/dew A)xilh=os")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != ref {
+		t.Error("expected '" + ref + "', got '" + s + "'")
+	}
+
+	if _, err := ToGreek("k/"); err == nil {
+		t.Error("expected error for accent on consonant")
+	}
+}
+
+func TestFromGreek(t *testing.T) {
+	tests := []struct {
+		greek, beta string
+	}{
+		{"Μῆνιν ἄειδε, θεά", "Mh=nin a)/eide, qea/"},
+		{"λόγος λόγοσ", "lo/gos lo/gos"},
+		{"ᾠδῇ Ἅιδης", "w)|dh=| A(/idhs"},
+		{"Πηληϊάδεω", "Phlhi+a/dew"},
+	}
+
+	for _, tt := range tests {
+		s, err := FromGreek(tt.greek)
+		if err != nil {
+			t.Error(err)
+		} else if s != tt.beta {
+			t.Error("expected '" + tt.beta + "', got '" + s + "'")
+		}
+	}
+
+	if _, err := FromGreek("ϡ"); err == nil {
+		t.Error("expected error for sampi")
+	}
+}
+
+func TestMust(t *testing.T) {
+	if s := MustToGreek("qea/"); s != "θεά" {
+		t.Error("expected 'θεά', got '" + s + "'")
+	}
+	if s := MustFromGreek("θεά"); s != "qea/" {
+		t.Error("expected 'qea/', got '" + s + "'")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustToGreek to panic")
+		}
+	}()
+	MustToGreek("k/")
+}
+
+func TestFromGreekMarkOrder(t *testing.T) {
+	// Breathing and accent in non-canonical order.
+	s, err := FromGreek("α\u0301\u0313ειδε")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "a)/eide" {
+		t.Error("expected 'a)/eide', got '" + s + "'")
+	}
+}
+
+func TestFromGreekSigma(t *testing.T) {
+	s, err := FromGreek("λόγος λόγος-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "lo/gos lo/goj-" {
+		t.Error("expected 'lo/gos lo/goj-', got '" + s + "'")
+	}
+}
+
+func TestFromGreekDialect(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		beta    string
+	}{
+		{DialectTypeGreek, "O(/mhros: Mh=nin"},
+		{DialectStandard, "*(/omhros1: *mh=nin"},
+		{DialectTLG, "*(/OMHROS1: *MH=NIN"},
+	}
+
+	for _, tt := range tests {
+		s, err := FromGreekDialect("\u1F4Dμηροσ: Μῆνιν", tt.dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != tt.beta {
+			t.Errorf("%s: expected %q, got %q", tt.dialect, tt.beta, s)
+		}
+	}
+}
+
+func TestFromGreekNormalization(t *testing.T) {
+	tests := []struct {
+		beta  string
+		forms []string
+	}{
+		{"a)/", []string{"\u1F04", "\u03B1\u0313\u0301", "\u1F00\u0301", "\u1F71\u0313", "\u03AC\u0343", "\u03B1\u0341\u0313"}},
+		{"w(=|", []string{"\u1FA7", "\u03C9\u0314\u0342\u0345", "\u1F67\u0345", "\u1F67\u1FBE", "\u03C9\u0345\u0342\u0314"}},
+		{"A|", []string{"\u1FBC", "\u0391\u0345", "\u0391\u1FBE"}},
+		{"i/+", []string{"\u0390", "\u1FD3", "\u03B9\u0344", "\u03CA\u0301", "\u03B9\u0301\u0308"}},
+	}
+
+	for _, tt := range tests {
+		for _, g := range tt.forms {
+			s, err := FromGreek(g)
+			if err != nil {
+				t.Errorf("%+q: %v", g, err)
+			} else if s != tt.beta {
+				t.Errorf("%+q: expected %q, got %q", g, tt.beta, s)
+			}
+		}
+	}
+}
+
+func TestConvertAll(t *testing.T) {
+	greek, errs := ConvertAll([]string{"qea/", "k/", "lo/gos"}, Options{})
+	if len(greek) != 3 || greek[0] != "θεά" || greek[2] != "λόγος" {
+		t.Errorf("unexpected output %q", greek)
+	}
+	if len(errs) != 3 || errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Errorf("expected an error for the second item only, got %v", errs)
+	}
+
+	if _, errs := ConvertAll([]string{"qea/"}, Options{Combining: true}); errs != nil {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	runes := Coverage("\u03E1 \u1FB1 \u03B1\u0304\u0301 \u03F2 \u1F04 \u00E9 \u0384\u0391 \u03D8")
+	want := []rune{'\u0304', '\u0384', '\u03D8', '\u03E1', '\u1FB1'}
+	if len(runes) != len(want) {
+		t.Fatalf("expected %U, got %U", want, runes)
+	}
+	for i := range want {
+		if runes[i] != want[i] {
+			t.Errorf("expected %U, got %U", want, runes)
+			break
+		}
+	}
+
+	if runes := Coverage("Μῆνιν ἄειδε, θεά"); runes != nil {
+		t.Errorf("expected no runes, got %U", runes)
+	}
+}
+
+func TestBetaFor(t *testing.T) {
+	tests := []struct {
+		cluster, beta string
+	}{
+		{"\u1F84", "a)/|"},
+		{"\u03B1\u0301\u0313\u0345", "a)/|"},
+		{"\u1F0C", "A)/"},
+		{"\u03C2", "j"},
+		{"\u0390", "i/+"},
+	}
+
+	for _, tt := range tests {
+		s, err := BetaFor(tt.cluster)
+		if err != nil {
+			t.Error(err)
+		} else if s != tt.beta {
+			t.Errorf("%q: expected %q, got %q", tt.cluster, tt.beta, s)
+		}
+	}
+
+	for _, s := range []string{"", "x", "\u03B1\u03B2", "\u03E1"} {
+		if _, err := BetaFor(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestConvertAppend(t *testing.T) {
+	src := []byte("Mh=nin a)/eide, qea/, Phlhi+a/dew A)xilh=os")
+	dst := []byte("> ")
+
+	s, err := ConvertAppend(dst, src, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(s) != "> Μῆνιν ἄειδε, θεά, Πηληϊάδεω Ἀχιλῆος" {
+		t.Errorf("unexpected %q", s)
+	}
+
+	buf := make([]byte, 0, 256)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ = ConvertAppend(buf[:0], src, Options{})
+	})
+	if allocs > 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
+	greek, bad, err := DecodeBytes([]byte("qea/ \xff lo/gos\xc3"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !utf8.Valid(greek) || string(greek) != "θεά \uFFFD λόγος\uFFFD" {
+		t.Errorf("unexpected %q", greek)
+	}
+	if len(bad) != 2 || bad[0] != 5 || bad[1] != 13 {
+		t.Errorf("expected bad bytes at 5 and 13, got %v", bad)
+	}
+
+	_, _, err = DecodeBytes([]byte("\xff\xfek/"), Options{})
+	if serr, ok := err.(*SyntaxError); !ok || serr.Pos.Offset != 3 {
+		t.Errorf("expected SyntaxError at offset 3, got %v", err)
+	}
+}
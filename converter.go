@@ -0,0 +1,30 @@
+package beta
+
+// A Converter converts Betacode to Greek with Options fixed at creation.
+// Unlike a Decoder or Writer, it keeps no state between conversions and is
+// safe for concurrent use, as long as the Handler, if any, is.
+type Converter struct {
+	opts Options
+}
+
+func NewConverter(opts Options) *Converter {
+	return &Converter{opts}
+}
+
+// Options returns the Options of the Converter.
+func (c *Converter) Options() Options {
+	return c.opts
+}
+
+// Convert converts Betacode to Greek like ToGreek. On error, the output up
+// to the error is returned.
+func (c *Converter) Convert(betacode string) (string, error) {
+	d := Decoder{Options: c.opts}
+	buf, err := d.convert(nil, betacode)
+	return string(buf), err
+}
+
+// ConvertBytes is like Convert for byte slices.
+func (c *Converter) ConvertBytes(betacode []byte) ([]byte, error) {
+	return ConvertAppend(nil, betacode, c.opts)
+}
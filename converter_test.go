@@ -0,0 +1,50 @@
+package beta
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestConverter(t *testing.T) {
+	c := NewConverter(Options{Combining: true, Canonical: true})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s, err := c.Convert("qea/ i/+")
+				if err != nil || s != "\u03B8\u03B5\u03B1\u0301 \u03B9\u0308\u0301" {
+					t.Errorf("unexpected %q, %v", s, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := c.ConvertBytes([]byte("k/")); err == nil {
+		t.Error("expected error for accent on consonant")
+	}
+}
+
+func TestSigmaPolicy(t *testing.T) {
+	tests := []struct {
+		sigma       SigmaPolicy
+		beta, greek string
+	}{
+		{SigmaAuto, "lo/gos lo/goj", "λόγος λόγος"},
+		{SigmaExplicit, "lo/gos lo/goj", "λόγοσ λόγος"},
+		{SigmaLunate, "*so/los lo/goj", "Ϲόλοϲ λόγοϲ"},
+	}
+
+	for _, tt := range tests {
+		s, err := NewConverter(Options{Sigma: tt.sigma}).Convert(tt.beta)
+		if err != nil {
+			t.Error(err)
+		} else if s != tt.greek {
+			t.Errorf("%q: expected %q, got %q", tt.beta, tt.greek, s)
+		}
+	}
+}
@@ -0,0 +1,1500 @@
+package beta
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Default delimiters of literal regions, which are copied without conversion.
+const (
+	DefaultLiteralOpen  = "{L"
+	DefaultLiteralClose = "L}"
+)
+
+// Options control the conversion of Betacode to Greek.
+type Options struct {
+	// Precombined UTF-8 (NFC) if false, combining diacritics otherwise.
+	Combining bool
+
+	// Emit combining diacritics in canonical order (see Sym.CanonicalString),
+	// which normalization doesn't change, and compose precombined output
+	// from it, so that i/+ becomes ΐ.
+	Canonical bool
+
+	// Emit capitals only, as in titles and inscriptions: without accents
+	// and breathings, with an iota subscript as a full iota, but with
+	// diaereses.
+	Caps bool
+
+	// Emit Greek without accents and breathings, as on inscriptions.
+	Plain bool
+
+	// How strictly Betacode is checked; by default, as leniently as by
+	// TypeGreek.
+	Level Level
+
+	// How sigmas are written; by default, s is final at the end of a word.
+	Sigma SigmaPolicy
+
+	// If not nil, Map is called for each piece of output with the input it
+	// was converted from, for the transfer of stand-off annotations. With
+	// RecoverVerbatim, the output of a word is one piece.
+	Map func(m Mapping)
+
+	// If not nil, OnWord is called at the end of each word and OnLine at
+	// the end of each line with its output and the ranges of input and
+	// output it spans, for building indexes and citations during the
+	// conversion. A word is a run of Greek symbols, which punctuation,
+	// hyphens and other text end; a line ends before a line break, or at
+	// the end of input, if it isn't empty. They are not called for the
+	// output to a Sink.
+	OnWord func(greek string, m Mapping)
+	OnLine func(greek string, m Mapping)
+
+	// Delimiters of literal regions, which are copied without conversion
+	// (the delimiters themselves are dropped). Literal regions let notes in
+	// other languages survive. If empty, DefaultLiteralOpen and
+	// DefaultLiteralClose are used.
+	LiteralOpen, LiteralClose string
+
+	// What to do with invalid Betacode; by default, it is an error.
+	Recovery Recovery
+
+	// Markers around words copied by RecoverVerbatim, e.g. "⟦" and "⟧".
+	// They may be empty.
+	VerbatimOpen, VerbatimClose string
+
+	// If not nil, Handler decides what to do with invalid Betacode instead
+	// of Recovery. It is called with the *SyntaxError (an *IncompleteError
+	// at the end of input), the position of the offending rune and the
+	// source of the invalid symbol.
+	Handler func(err error, pos Position, source string) Action
+
+	// The output of the Replace Action: Replacement, U+FFFD if 0 and
+	// nothing if negative, followed by the source of the invalid symbol
+	// between ReplaceOpen and ReplaceClose, like "⟦" and "⟧", if either is
+	// not empty.
+	Replacement               rune
+	ReplaceOpen, ReplaceClose string
+
+	// Whether numeric markers and citation tags at the end of words, as
+	// in Perseus texts, are recognised, and what becomes of them. By
+	// default, they are text like any other.
+	Markers MarkerPolicy
+
+	// If not nil and Markers are recognised, Marker is called for each.
+	Marker func(m Marker)
+
+	// What an asterisk means; by default, it makes the next letter a
+	// capital, as in Standard Betacode.
+	Asterisk AsteriskPolicy
+
+	// The Betacode read. TypeGreek and Standard Betacode are read alike,
+	// with capital letters and asterisks both making capitals. With
+	// DialectTLG, only an asterisk does, and letters of either case are
+	// the same. DialectKeyboard reads the keys of the Greek keyboard layout
+	// as the letters on them.
+	Dialect Dialect
+
+	// Letters of either case are the same, as with DialectTLG, so that
+	// only an asterisk makes a capital, for texts that use capital
+	// letters freely.
+	IgnoreCase bool
+
+	// Numeric character references, like &#x3B1; or &#945;, and escapes
+	// like \u03B1 stand for their character, as if it were in the input,
+	// for text scraped from the web. Otherwise, \u is a grave accent and
+	// an upsilon.
+	Escapes bool
+
+	// What digits mean; by default, they are text like any other.
+	Digits DigitPolicy
+
+	// Substitutions for quirks of a corpus, applied to the input before
+	// it is read as Betacode; see ParseRules.
+	Rules []Rule
+
+	// If not nil, Warn is called for input that is unusual, but converted:
+	// a second accent or breathing on a symbol, which replaces the first,
+	// an asterisk without letter, which is dropped, and j for final sigma
+	// in Standard Betacode. The Diagnostics have the position of the
+	// offending rune and the source of its symbol as their Word.
+	Warn func(d Diagnostic)
+
+	// Every piece of output is checked to be in NFC, also after the output
+	// before it, and every Greek symbol to be a single precomposed
+	// character, so that no tool normalizes the output differently; a
+	// piece that isn't is written nonetheless and reported by a
+	// *NormError. Combining output always fails.
+	VerifyNFC bool
+}
+
+// An Action tells the Decoder what to do with an invalid symbol.
+type Action int
+
+const (
+	Skip    Action = iota // Discard the symbol
+	Replace               // Output Options.Replacement instead of the symbol
+	Abort                 // Report the error
+)
+
+// A Mapping relates a range of output bytes to the range of input bytes it
+// was converted from. Delimiters of literal regions produce no output.
+type Mapping struct {
+	InStart, InEnd   int
+	OutStart, OutEnd int
+}
+
+// A Level is a set of rules for valid Betacode; each includes the ones before.
+type Level int
+
+const (
+	// Diacritics on the wrong kind of letter are invalid, but a later
+	// accent or breathing replaces an earlier one.
+	LevelPermissive Level = iota
+
+	// A second accent or breathing on a symbol is invalid, and so is
+	// Betacode that cannot occur in Greek: a circumflex on epsilon or
+	// omicron, an iota subscript on vowels other than alpha, eta and
+	// omega, or a diaeresis on vowels other than iota and upsilon.
+	LevelStandard
+
+	// Breathings are only valid on the initial vowel or diphthong of a
+	// word or on rho. Words with a koronis, like ka)gw/, are invalid too.
+	LevelPedantic
+)
+
+var levelNames = []string{"permissive", "standard", "pedantic"}
+
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return "Level(" + strconv.Itoa(int(l)) + ")"
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the Level of the given name, as returned by String.
+func ParseLevel(name string) (Level, error) {
+	for i, n := range levelNames {
+		if n == name {
+			return Level(i), nil
+		}
+	}
+	return 0, errors.New("unknown level " + strconv.Quote(name))
+}
+
+// A SigmaPolicy decides the form of sigma.
+type SigmaPolicy int
+
+const (
+	// s becomes final sigma at the end of a word, j is always final.
+	SigmaAuto SigmaPolicy = iota
+
+	// s is always medial, only j is final, for texts that mark final sigma.
+	SigmaExplicit
+
+	// All sigmas are lunate (ϲ, Ϲ), as in editions of papyri.
+	SigmaLunate
+)
+
+// A MarkerPolicy decides what becomes of the markers at the end of words.
+type MarkerPolicy int
+
+const (
+	// Markers are not recognised; a sigma before digits stays medial.
+	MarkersText MarkerPolicy = iota
+
+	// Markers end their word and are copied unchanged.
+	MarkersKeep
+
+	// Markers end their word and are dropped.
+	MarkersStrip
+)
+
+// A DigitPolicy decides what digits mean.
+type DigitPolicy int
+
+const (
+	// Digits are text; a sigma before digits stays medial.
+	DigitsText DigitPolicy = iota
+
+	// Digits after s, [, ], ", % and # are part of the codes of Standard
+	// Betacode: s1 is medial, s2 final and s3 lunate sigma, [1 and ]1 are
+	// parentheses, "1 is a low quotation mark, %1 is a question mark, #2
+	// is stigma and so on. All other digits are text that ends a word.
+	DigitsCodes
+)
+
+// A Marker is a numeric marker or citation tag directly after a word, like
+// the 2 of lo/gos2 or the [12] of lo/gos[12].
+type Marker struct {
+	Text string   // The marker as in the input
+	Pos  Position // Position of its first rune
+	Out  int      // Offset of the output after the word
+}
+
+// An AsteriskPolicy decides the meaning of *.
+type AsteriskPolicy int
+
+const (
+	// An asterisk makes the next letter a capital; diacritics may come
+	// between them. An asterisk without letter is dropped, except at the
+	// end of input, where it is an *IncompleteError.
+	AsteriskStandard AsteriskPolicy = iota
+
+	// Like AsteriskStandard, but an asterisk without letter is always an
+	// error.
+	AsteriskStrict
+
+	// Asterisks are dropped, for TypeGreek texts with stray ones.
+	AsteriskIgnore
+)
+
+// A Recovery is a policy for invalid Betacode.
+type Recovery int
+
+const (
+	// Report an error and discard the offending symbol.
+	RecoverError Recovery = iota
+
+	// Copy the word containing the error unchanged, in VerbatimOpen and
+	// VerbatimClose, and report no error. A word is a run of Betacode
+	// characters; its output is held back until it ends.
+	RecoverVerbatim
+)
+
+// literalDelims returns the effective literal region delimiters.
+func (o *Options) literalDelims() (open, close string) {
+	if o.LiteralOpen == "" || o.LiteralClose == "" {
+		return DefaultLiteralOpen, DefaultLiteralClose
+	}
+	return o.LiteralOpen, o.LiteralClose
+}
+
+// A SyntaxError reports invalid Betacode.
+type SyntaxError struct {
+	Pos Position // Position of the offending rune
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
+// A NormError reports output that fails Options.VerifyNFC.
+type NormError struct {
+	Offset int    // Offset of the input the output was converted from
+	Output string // The piece of output
+	Msg    string
+}
+
+func (e *NormError) Error() string {
+	return "offset " + strconv.Itoa(e.Offset) + ": " + strconv.Quote(e.Output) + " " + e.Msg
+}
+
+// An IncompleteError reports a symbol left incomplete at the end of input,
+// like an asterisk, possibly with diacritics, without its letter. Pos is
+// the start of the symbol.
+type IncompleteError struct {
+	SyntaxError
+	Source string // Source of the symbol
+}
+
+func (e *IncompleteError) Error() string {
+	return e.Pos.String() + ": incomplete symbol " + strconv.Quote(e.Source) + ": " + e.Msg
+}
+
+// Unwrap returns the SyntaxError, for errors.As.
+func (e *IncompleteError) Unwrap() error {
+	return &e.SyntaxError
+}
+
+// A Decoder converts Betacode to Greek one rune at a time, for input methods
+// and editors where an io.Writer is awkward. A symbol is held back until the
+// next rune shows that it is complete; only then is it emitted. Likewise,
+// runes that may start a literal region delimiter are held back.
+type Decoder struct {
+	Options
+
+	sym     Sym
+	literal bool       // Inside a literal region
+	held    []heldRune // Runes that may be part of a delimiter
+	pos     Position   // Position of the next rune
+	buf     []byte
+	symSrc  []byte   // Source of sym
+	symIn   [2]int   // Input range of sym
+	symPos  Position // Position of sym
+	cur     [2]int   // Input range of the rune being processed
+	curPos  Position // Position of the rune being processed
+	out     int      // Bytes of output
+	stats   Stats
+	inWord  bool   // A symbol of the current word has been emitted
+	prev    Sym    // The previous symbol of the word
+	index   int    // Index of sym in the word
+	lunate  bool   // sym is a lunate sigma, as by s3
+	marks   []byte // Marks of Rules for sym
+
+	// With RecoverVerbatim, the output and source of the current word
+	word      []byte
+	src       []byte
+	failed    bool
+	wordStats Stats    // stats before the word
+	wordIn    [2]int   // Input range of the word
+	wordPos   Position // Position of the word
+	wordErr   error    // First error of the word
+	wordSyms  []Sym    // Symbols of the word, for onPiece
+	buffering bool     // Output goes to word
+
+	// With dual, the output is precombined, and alt receives the same
+	// output with combining diacritics; altWord is its word.
+	dual    bool
+	alt     []byte
+	altWord []byte
+
+	// If not nil, the output goes to sink instead. sinkWord holds the
+	// symbols of the current word with RecoverVerbatim.
+	sink     Sink
+	sinkWord []Sym
+	sinkErr  error // First error of the sink
+	normErr  error // Error of VerifyNFC not reported yet
+
+	// With OnWord or OnLine, the current word, if open, and line
+	wordSeg segment
+	lineSeg segment
+
+	// If not nil, classify is called with the Class of each piece of
+	// input as it is read, for Classify.
+	classify func(c Class, in [2]int)
+
+	// If not nil, onPiece is called with RecoverVerbatim for each word,
+	// rune of text and delimiter read, for the scanners of words and
+	// tokens; pieceText holds the text of the piece.
+	onPiece   func(p piece)
+	pieceText []byte
+}
+
+// A segment is the output of a word or line so far and the start of its
+// range, with the end so far for a word.
+type segment struct {
+	out  []byte
+	m    Mapping
+	open bool
+}
+
+// Stats are counts of the conversion.
+type Stats struct {
+	Symbols int // Greek symbols emitted
+	Words   int // Words emitted, which includes hyphenated halves
+	Errors  int // Errors recovered from by Handler or Recovery
+	Bytes   int // Bytes written by a Writer
+}
+
+// A heldRune is an input rune that is not processed yet.
+type heldRune struct {
+	r       rune
+	size    int // Bytes of input
+	pos     Position
+	escaped bool // r was escaped
+	subst   bool // r comes from a Rule
+}
+
+// Push adds r to the input and returns the Greek output it completes, if any.
+// Errors are of type *SyntaxError. After an error, the offending symbol is
+// discarded and the Decoder can be used further.
+func (d *Decoder) Push(r rune) (emit string, err error) {
+	d.buf, err = d.push(d.buf[:0], r)
+	return string(d.buf), err
+}
+
+// Flush ends the input and returns the pending output, if any. A pending
+// sigma becomes final sigma. A pending incomplete symbol is an
+// *IncompleteError. A literal region stays open across Flush, so that input
+// can be flushed line by line, until it is closed or the Decoder is Reset.
+func (d *Decoder) Flush() (emit string, err error) {
+	d.buf, err = d.end(d.buf[:0])
+	return string(d.buf), err
+}
+
+// Reset discards the pending input and starts counting positions anew.
+func (d *Decoder) Reset() {
+	d.sym.Reset()
+	d.symSrc = d.symSrc[:0]
+	d.literal = false
+	d.held = d.held[:0]
+	d.pos = Position{}
+	d.symIn = [2]int{}
+	d.symPos = Position{}
+	d.cur = [2]int{}
+	d.curPos = Position{}
+	d.out = 0
+	d.stats = Stats{}
+	d.inWord = false
+	d.prev = Sym{}
+	d.index = 0
+	d.lunate = false
+	d.marks = d.marks[:0]
+	d.word = d.word[:0]
+	d.src = d.src[:0]
+	d.failed = false
+	d.wordStats = Stats{}
+	d.wordIn = [2]int{}
+	d.wordPos = Position{}
+	d.wordErr = nil
+	d.wordSyms = d.wordSyms[:0]
+	d.buffering = false
+	d.alt = d.alt[:0]
+	d.altWord = d.altWord[:0]
+	d.sinkWord = d.sinkWord[:0]
+	d.sinkErr = nil
+	d.normErr = nil
+	d.wordSeg = segment{out: d.wordSeg.out[:0]}
+	d.lineSeg = segment{out: d.lineSeg.out[:0]}
+	d.pieceText = d.pieceText[:0]
+}
+
+// push appends the output completed by r to dst.
+func (d *Decoder) push(dst []byte, r rune) ([]byte, error) {
+	return d.pushSize(dst, r, utf8.RuneLen(r))
+}
+
+// pushSize is push for a rune decoded from size bytes of input.
+func (d *Decoder) pushSize(dst []byte, r rune, size int) ([]byte, error) {
+	if d.pos.Line == 0 {
+		d.pos = Position{Line: 1, Col: 1}
+	}
+	d.held = append(d.held, heldRune{r: r, size: size, pos: d.pos})
+	d.pos.advanceSize(r, size)
+
+	dst, err := d.process(dst, false)
+	return dst, d.verified(err)
+}
+
+// warn reports unusual input at pos, in the symbol src, to Warn.
+func (d *Decoder) warn(pos Position, src, msg string) {
+	if d.Warn != nil {
+		d.Warn(Diagnostic{Pos: pos, Word: src, Msg: msg, Severity: Warning})
+	}
+}
+
+// verified returns err or, if nil, the pending error of VerifyNFC.
+func (d *Decoder) verified(err error) error {
+	if err == nil {
+		err, d.normErr = d.normErr, nil
+	}
+	return err
+}
+
+// end appends the output pending at the end of input.
+func (d *Decoder) end(dst []byte) ([]byte, error) {
+	dst, err := d.process(dst, true)
+	if err == nil && d.sym.ast {
+		if d.verbatim() {
+			d.failWord(errAsterisk)
+		} else {
+			ierr := &IncompleteError{SyntaxError{d.symPos, errAsterisk.Error()}, string(d.symSrc)}
+			dst, err = d.fail(dst, ierr, d.symPos, d.pos.Offset)
+		}
+		d.sym.Reset()
+	}
+	dst = d.finishWord(dst)
+	d.endSegments()
+	return dst, d.verified(err)
+}
+
+// process converts the held runes as far as possible. Unless atEnd, a
+// proper prefix of a delimiter is kept for later.
+func (d *Decoder) process(dst []byte, atEnd bool) ([]byte, error) {
+	for len(d.held) > 0 {
+		open, close := d.literalDelims()
+		delim := open
+		if d.literal {
+			delim = close
+		}
+
+		switch matchHeld(d.held, delim) {
+		case matchPrefix:
+			if !atEnd {
+				return dst, nil
+			}
+
+		case matchFull:
+			last := d.held[len(d.held)-1]
+			in := [2]int{d.held[0].pos.Offset, last.pos.Offset + last.size}
+			d.classified(ClassStructural, in)
+			if !d.literal {
+				dst = d.finishWord(dst)
+			}
+			d.piece(pieceDelim, in, d.held[0].pos, nil)
+			d.literal = !d.literal
+			d.held = d.held[:0]
+			continue
+		}
+
+		if len(d.Rules) > 0 && !d.literal && !d.held[0].subst {
+			if rule, ok := d.rule(d.held[0].r); ok {
+				var err error
+				if dst, err = d.applyRule(dst, rule); err != nil {
+					return dst, err
+				}
+				continue
+			}
+		}
+
+		if d.Escapes && !d.literal {
+			n, r, wait := d.matchEscape(atEnd)
+			if wait {
+				return dst, nil
+			}
+			if n > 0 {
+				first, last := d.held[0], d.held[n-1]
+				size := last.pos.Offset + last.size - first.pos.Offset
+				d.held[0] = heldRune{r: r, size: size, pos: first.pos, escaped: true}
+				d.held = append(d.held[:1], d.held[n:]...)
+			}
+		}
+
+		if d.Markers != MarkersText && !d.literal {
+			n, wait := d.matchMarker(atEnd)
+			if wait {
+				return dst, nil
+			}
+			if n > 0 {
+				dst = d.marker(dst, n)
+				continue
+			}
+		}
+
+		if d.Digits == DigitsCodes && !d.literal && !d.held[0].escaped {
+			c, wait := lexCode(len(d.held), func(i int) rune { return d.code(d.held[i].r) }, atEnd)
+			if wait {
+				return dst, nil
+			}
+
+			switch c.kind {
+			case codeSigma:
+				h, digit := d.held[0], d.held[1]
+				d.held = append(d.held[:0], d.held[2:]...)
+				var err error
+				if dst, err = d.addRune(dst, h); err != nil {
+					return dst, err
+				}
+				dst = d.addSigma(dst, digit)
+				d.classified(ClassStructural, d.cur)
+				continue
+
+			case codeSign:
+				first, last := d.held[0], d.held[c.n-1]
+				d.cur = [2]int{first.pos.Offset, last.pos.Offset + last.size}
+				d.curPos = first.pos
+				d.held = append(d.held[:0], d.held[c.n:]...)
+				d.classified(ClassStructural, d.cur)
+				if d.verbatim() {
+					dst = d.verbatimText(dst, c.r)
+				} else {
+					dst = d.addText(dst, c.r)
+				}
+				continue
+			}
+		}
+
+		h := d.held[0]
+		d.held = append(d.held[:0], d.held[1:]...)
+		var err error
+		if dst, err = d.addRune(dst, h); err != nil {
+			return dst, err
+		}
+	}
+
+	return dst, nil
+}
+
+// addRune processes the held rune h.
+func (d *Decoder) addRune(dst []byte, h heldRune) ([]byte, error) {
+	d.cur = [2]int{h.pos.Offset, h.pos.Offset + h.size}
+	d.curPos = h.pos
+
+	if d.literal {
+		d.classified(ClassText, d.cur)
+		if d.onPiece != nil {
+			d.pieceText = appendRune(d.pieceText[:0], h.r)
+			d.piece(pieceLiteral, d.cur, h.pos, d.pieceText)
+		}
+		return d.appendMapped(dst, d.cur, h.r), nil
+	}
+	d.classified(codeClass(d.code(h.r)), d.cur)
+
+	// The rune after an asterisk without letter is processed after the
+	// error.
+	if d.sym.ast && d.Asterisk == AsteriskStrict && !strings.ContainsRune(validCodes, d.code(h.r)) {
+		var err error
+		if d.verbatim() {
+			d.failWord(errAsterisk)
+		} else {
+			dst, err = d.fail(dst, &SyntaxError{h.pos, errAsterisk.Error()}, h.pos, d.cur[0])
+		}
+		d.sym.Reset()
+		if err != nil {
+			return dst, err
+		}
+	}
+
+	if d.verbatim() {
+		return d.addVerbatim(dst, h.r), nil
+	}
+
+	var err error
+	if dst, err = d.add(dst, h.r); err != nil {
+		serr := &SyntaxError{Pos: h.pos, Msg: err.Error()}
+		return d.fail(dst, serr, h.pos, d.cur[1])
+	}
+	return dst, nil
+}
+
+// fail handles the error serr of the symbol in symSrc at pos, whose input
+// ends at end, by the Handler. It returns serr unless the Handler recovers
+// from it.
+func (d *Decoder) fail(dst []byte, serr error, pos Position, end int) ([]byte, error) {
+	src := string(d.symSrc)
+	in := [2]int{d.symIn[0], end}
+	if len(src) == 0 {
+		in[0] = d.cur[0]
+	}
+	d.symSrc = d.symSrc[:0]
+
+	if d.Handler == nil {
+		return dst, serr
+	}
+	switch d.Handler(serr, pos, src) {
+	case Skip:
+	case Replace:
+		dst = d.appendReplacement(dst, in, src)
+	default:
+		return dst, serr
+	}
+	d.stats.Errors++
+	return dst, nil
+}
+
+// Results of matchHeld.
+const (
+	matchNone = iota
+	matchPrefix
+	matchFull
+)
+
+// matchHeld tells whether the held runes are delim or a proper prefix of it.
+func matchHeld(held []heldRune, delim string) int {
+	i := 0
+	for _, r := range delim {
+		if i == len(held) {
+			return matchPrefix
+		}
+		if held[i].r != r {
+			return matchNone
+		}
+		i++
+	}
+
+	if i == len(held) {
+		return matchFull
+	}
+	return matchNone
+}
+
+// rule returns the Rule for r, if any.
+func (d *Decoder) rule(r rune) (Rule, bool) {
+	for _, rule := range d.Rules {
+		if rule.From == r {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Error of a mark of a Rule without letter
+var errMark = errors.New("mark without base character")
+
+// applyRule applies rule to the first held rune: a mark is added to the
+// pending symbol, and otherwise the rune is replaced by rule.To.
+func (d *Decoder) applyRule(dst []byte, rule Rule) ([]byte, error) {
+	h := d.held[0]
+
+	if rule.Mark == "" {
+		sub := make([]heldRune, 0, len(rule.To)+len(d.held)-1)
+		for _, r := range rule.To {
+			sub = append(sub, heldRune{r: r, pos: h.pos, subst: true})
+		}
+		if len(sub) > 0 {
+			sub[0].size = h.size
+		}
+		d.held = append(sub, d.held[1:]...)
+		return dst, nil
+	}
+
+	d.held = append(d.held[:0], d.held[1:]...)
+	d.cur = [2]int{h.pos.Offset, h.pos.Offset + h.size}
+	d.curPos = h.pos
+	d.classified(ClassAccent, d.cur)
+	if d.verbatim() {
+		d.startWord()
+		d.src = appendRune(d.src, h.r)
+		d.wordIn[1] = d.cur[1]
+		if d.sym.Base == 0 {
+			d.failWord(errMark)
+		}
+	} else if d.sym.Base == 0 {
+		return d.fail(dst, &SyntaxError{h.pos, errMark.Error()}, h.pos, d.cur[1])
+	}
+	if d.sym.Base != 0 {
+		d.addSrc(h.r)
+		d.marks = append(d.marks, rule.Mark...)
+	}
+	return dst, nil
+}
+
+// matchEscape returns the number of held runes that are a character
+// reference or escape and the rune they stand for, or tells to wait for
+// more input.
+func (d *Decoder) matchEscape(atEnd bool) (n int, r rune, wait bool) {
+	h := d.held
+	if h[0].escaped || h[0].r != '&' && h[0].r != '\\' {
+		return 0, 0, false
+	}
+
+	// at returns the held rune i, or -1 if there is none (yet).
+	at := func(i int) rune {
+		if i < len(h) {
+			return h[i].r
+		}
+		return -1
+	}
+	more := func(i int) bool {
+		return i >= len(h) && !atEnd
+	}
+
+	i, base, digits := 1, 16, 4
+	if h[0].r == '\\' {
+		if more(1) {
+			return 0, 0, true
+		}
+		if at(1) != 'u' {
+			return 0, 0, false
+		}
+		i++
+	} else {
+		if more(1) {
+			return 0, 0, true
+		}
+		if at(1) != '#' {
+			return 0, 0, false
+		}
+		i++
+		if more(i) {
+			return 0, 0, true
+		}
+		if at(i) == 'x' || at(i) == 'X' {
+			i++
+		} else {
+			base = 10
+		}
+		digits = 0 // As many as there are, up to the semicolon
+	}
+
+	v, start := 0, i
+	for digits == 0 || i-start < digits {
+		if more(i) {
+			return 0, 0, true
+		}
+		x := digitVal(at(i), base)
+		if x < 0 {
+			break
+		}
+		if v = v*base + x; v > unicode.MaxRune {
+			return 0, 0, false
+		}
+		i++
+	}
+	switch {
+	case i == start, digits > 0 && i-start < digits, !utf8.ValidRune(rune(v)):
+		return 0, 0, false
+	case digits == 0:
+		if at(i) != ';' {
+			return 0, 0, false
+		}
+		i++
+	}
+	return i, rune(v), false
+}
+
+// digitVal returns the value of the digit c in base 10 or 16, or -1.
+func digitVal(c rune, base int) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case base == 16 && 'a' <= c && c <= 'f':
+		return int(c-'a') + 10
+	case base == 16 && 'A' <= c && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
+
+// matchMarker returns the number of held runes that are a marker after the
+// pending symbol, or tells to wait for more input. Unless atEnd, a marker
+// is only complete with the rune after it.
+func (d *Decoder) matchMarker(atEnd bool) (n int, wait bool) {
+	if d.sym.Base == 0 {
+		return 0, false
+	}
+
+	i := 0
+	bracket := d.held[0].r == '['
+	if bracket {
+		i++
+	}
+	for i < len(d.held) && '0' <= d.held[i].r && d.held[i].r <= '9' {
+		i++
+	}
+	if i == len(d.held) && !atEnd {
+		return 0, bracket || i > 0
+	}
+
+	switch {
+	case !bracket:
+		return i, false
+	case i > 1 && i < len(d.held) && d.held[i].r == ']':
+		return i + 1, false
+	}
+	return 0, false
+}
+
+// marker ends the word and appends the marker of the first n held runes
+// or drops it, as by the MarkerPolicy.
+func (d *Decoder) marker(dst []byte, n int) []byte {
+	dst = d.finishWord(dst)
+
+	var text []byte
+	for _, h := range d.held[:n] {
+		text = appendRune(text, h.r)
+	}
+	last := d.held[n-1]
+	in := [2]int{d.held[0].pos.Offset, last.pos.Offset + last.size}
+	d.classified(ClassStructural, in)
+
+	if d.Marker != nil {
+		d.Marker(Marker{Text: string(text), Pos: d.held[0].pos, Out: d.out})
+	}
+	if d.Markers == MarkersKeep {
+		d.piece(pieceText, in, d.held[0].pos, text)
+		m := len(dst)
+		dst = append(dst, text...)
+		if d.sink != nil {
+			dst = d.divert(dst, m)
+		}
+		if d.dual {
+			d.alt = append(d.alt, text...)
+		}
+		d.mapped(in, dst[m:], false)
+	}
+
+	d.held = append(d.held[:0], d.held[n:]...)
+	return dst
+}
+
+// Error of an asterisk without letter
+var errAsterisk = errors.New("asterisk without base character")
+
+// add appends the output completed by r to dst.
+func (d *Decoder) add(dst []byte, r rune) ([]byte, error) {
+	if r == '*' && d.Asterisk == AsteriskIgnore {
+		return dst, nil
+	}
+	r = d.code(r)
+
+	// End of word detected
+	if !strings.ContainsRune(validCodes, r) {
+		return d.addText(dst, r), nil
+	}
+	if r == 'j' && d.Dialect.asterisks() {
+		d.warn(d.curPos, "j", "j for final sigma is not Standard Betacode")
+	}
+
+	// On error, symSrc is left with the source of the invalid symbol.
+	old := d.sym
+	if d.sym.Add(r) {
+		d.addSrc(r)
+		if err := d.validate(old, r); err != nil {
+			d.sym.Reset()
+			d.marks = d.marks[:0]
+			return dst, err
+		}
+		return dst, nil
+	}
+	if err := d.sym.Err(); err != nil {
+		d.sym.Reset()
+		d.marks = d.marks[:0]
+		d.addSrc(r)
+		return dst, err
+	}
+
+	// We encountered the base rune of the next symbol. Output the current
+	// symbol and add the base to the next one.
+	dst = d.appendSym(dst)
+	d.addSrc(r)
+	if !d.sym.Add(r) {
+		err := d.sym.Err()
+		d.sym.Reset()
+		return dst, err
+	}
+
+	return dst, nil
+}
+
+// addText appends the pending symbol and r, which is not Betacode.
+func (d *Decoder) addText(dst []byte, r rune) []byte {
+	if finalSigma(r) || d.Digits == DigitsCodes && unicode.IsDigit(r) {
+		dst = d.endWord(dst)
+	} else {
+		dst = d.appendSym(dst)
+	}
+	return d.appendMapped(dst, d.cur, r)
+}
+
+// addSigma completes the pending sigma with h, the digit of its code.
+func (d *Decoder) addSigma(dst []byte, h heldRune) []byte {
+	d.cur = [2]int{h.pos.Offset, h.pos.Offset + h.size}
+	d.curPos = h.pos
+
+	if !d.verbatim() {
+		return d.sigma(dst, h.r)
+	}
+	d.src = appendRune(d.src, h.r)
+	d.wordIn[1] = d.cur[1]
+	if !d.failed {
+		d.buffering = true
+		d.word = d.sigma(d.word, h.r)
+		d.buffering = false
+	}
+	return dst
+}
+
+// sigma appends the pending sigma in the form given by digit.
+func (d *Decoder) sigma(dst []byte, digit rune) []byte {
+	// The s was invalid and has been discarded.
+	if d.sym.Base != 's' && d.sym.Base != 'S' {
+		return dst
+	}
+
+	d.addSrc(digit)
+	switch {
+	case digit == '2' && d.sym.Base == 's':
+		d.sym.Base = 'j'
+	case digit == '3':
+		d.lunate = true
+	}
+	return d.appendSym(dst)
+}
+
+// addSrc adds the rune being processed, r, to the source of sym.
+func (d *Decoder) addSrc(r rune) {
+	if len(d.symSrc) == 0 {
+		d.symIn[0] = d.cur[0]
+		d.symPos = d.curPos
+	}
+	d.symSrc = appendRune(d.symSrc, r)
+	d.symIn[1] = d.cur[1]
+}
+
+// appendMapped appends r, converted from the input range in, to dst.
+func (d *Decoder) appendMapped(dst []byte, in [2]int, r rune) []byte {
+	n := len(dst)
+	dst = appendRune(dst, r)
+	if d.sink != nil {
+		return d.divert(dst, n)
+	}
+	if d.dual {
+		d.appendAlt(r)
+	}
+	d.mapped(in, dst[n:], false)
+	return dst
+}
+
+// divert writes the output dst[n:] to the sink as raw text and returns dst
+// without it.
+func (d *Decoder) divert(dst []byte, n int) []byte {
+	if d.sinkErr == nil && len(dst) > n {
+		d.sinkErr = d.sink.WriteRaw(dst[n:])
+	}
+	return dst[:n]
+}
+
+// sinkSym writes sym to the sink, or keeps it for the end of the word.
+func (d *Decoder) sinkSym(sym Sym) {
+	switch {
+	case d.buffering:
+		d.sinkWord = append(d.sinkWord, sym)
+	case d.sinkErr == nil:
+		d.sinkErr = d.sink.WriteSymbol(sym)
+	}
+}
+
+// sinkBoundary writes the end of a word to the sink.
+func (d *Decoder) sinkBoundary() {
+	if d.sinkErr == nil {
+		d.sinkErr = d.sink.WriteBoundary()
+	}
+}
+
+// mapped notes the output out converted from the input range in, which is
+// part of a word if word.
+func (d *Decoder) mapped(in [2]int, out []byte, word bool) {
+	if d.buffering || len(out) == 0 {
+		return
+	}
+	m := Mapping{in[0], in[1], d.out, d.out + len(out)}
+	if d.VerifyNFC && d.normErr == nil {
+		d.verifyNFC(in[0], out)
+	}
+	if d.Map != nil {
+		d.Map(m)
+	}
+	if d.OnWord != nil || d.OnLine != nil {
+		d.segment(m, out, word)
+	}
+	d.out += len(out)
+}
+
+// singleChar reports whether the Greek symbol p is a single character,
+// followed by an iota if adscript.
+func singleChar(p []byte, adscript bool) bool {
+	n := utf8.RuneCount(p)
+	if adscript {
+		n--
+	}
+	return n == 1
+}
+
+// verifyNFC checks the output out, converted from the input at offset, for
+// VerifyNFC.
+func (d *Decoder) verifyNFC(offset int, out []byte) {
+	msg := ""
+	switch {
+	case !norm.NFC.IsNormal(out):
+		msg = "is not in NFC"
+	case d.out > 0 && !norm.NFC.Properties(out).BoundaryBefore():
+		msg = "combines with the output before it"
+	default:
+		return
+	}
+	d.normErr = &NormError{Offset: offset, Output: string(out), Msg: msg}
+}
+
+// segment adds the output out, mapped by m, to the current word, if word,
+// and line, and reports those that it ends.
+func (d *Decoder) segment(m Mapping, out []byte, word bool) {
+	switch {
+	case !word:
+		d.endWordSeg()
+	case !d.wordSeg.open:
+		d.wordSeg = segment{out: d.wordSeg.out[:0], m: m, open: true}
+	default:
+		d.wordSeg.m.InEnd, d.wordSeg.m.OutEnd = m.InEnd, m.OutEnd
+	}
+	if word {
+		d.wordSeg.out = append(d.wordSeg.out, out...)
+	}
+
+	if word || string(out) != "\n" {
+		d.lineSeg.out = append(d.lineSeg.out, out...)
+		return
+	}
+	d.endLineSeg(m.InStart)
+	d.lineSeg.m.InStart = m.InEnd
+	d.lineSeg.m.OutStart = m.OutEnd
+}
+
+// endWordSeg reports the end of the current word, if any.
+func (d *Decoder) endWordSeg() {
+	if d.wordSeg.open && d.OnWord != nil {
+		d.OnWord(string(d.wordSeg.out), d.wordSeg.m)
+	}
+	d.wordSeg.open = false
+}
+
+// endLineSeg reports the current line, which ends at the input offset end
+// and the current output, and starts the next there.
+func (d *Decoder) endLineSeg(end int) {
+	if d.OnLine != nil {
+		m := d.lineSeg.m
+		m.InEnd, m.OutEnd = end, d.out
+		d.OnLine(string(d.lineSeg.out), m)
+	}
+	d.lineSeg.out = d.lineSeg.out[:0]
+	d.lineSeg.m.InStart, d.lineSeg.m.OutStart = end, d.out
+}
+
+// endSegments reports the end of the word and of the line, unless it is
+// empty, at the end of input.
+func (d *Decoder) endSegments() {
+	d.endWordSeg()
+	if len(d.lineSeg.out) > 0 || d.pos.Offset > d.lineSeg.m.InStart {
+		d.endLineSeg(d.pos.Offset)
+	}
+}
+
+// appendReplacement appends the replacement of the invalid symbol src,
+// converted from the input range in, to dst.
+func (d *Decoder) appendReplacement(dst []byte, in [2]int, src string) []byte {
+	n := len(dst)
+	switch {
+	case d.Replacement == 0:
+		dst = appendRune(dst, utf8.RuneError)
+	case d.Replacement > 0:
+		dst = appendRune(dst, d.Replacement)
+	}
+	if d.ReplaceOpen != "" || d.ReplaceClose != "" {
+		dst = append(dst, d.ReplaceOpen...)
+		dst = append(dst, src...)
+		dst = append(dst, d.ReplaceClose...)
+	}
+	if d.sink != nil {
+		return d.divert(dst, n)
+	}
+	if d.dual {
+		d.alt = append(d.alt, dst[n:]...)
+	}
+	d.mapped(in, dst[n:], true)
+	return dst
+}
+
+// appendAlt appends r to the combining output of a dual Decoder.
+func (d *Decoder) appendAlt(r rune) {
+	if d.buffering {
+		d.altWord = appendRune(d.altWord, r)
+	} else {
+		d.alt = appendRune(d.alt, r)
+	}
+}
+
+// classified reports the Class of the input in to classify, if set.
+func (d *Decoder) classified(c Class, in [2]int) {
+	if d.classify != nil && in[0] < in[1] {
+		d.classify(c, in)
+	}
+}
+
+// code returns the Betacode code that r is read as in the dialect.
+func (d *Decoder) code(r rune) rune {
+	if d.foldCase() && 'A' <= r && r <= 'Z' {
+		r += 'a' - 'A'
+	}
+	if codes := d.Dialect.table().Codes; codes != nil {
+		r = remap(r, codes)
+	}
+	return r
+}
+
+// foldCase reports whether letters of either case are the same.
+func (d *Decoder) foldCase() bool {
+	return d.IgnoreCase || d.Dialect.table().Capitals
+}
+
+// verbatim reports whether RecoverVerbatim is in effect.
+func (d *Decoder) verbatim() bool {
+	return d.Recovery == RecoverVerbatim && d.Handler == nil
+}
+
+// addVerbatim is add for RecoverVerbatim. The output of a word is kept
+// until it ends, to be replaced by its source on error.
+func (d *Decoder) addVerbatim(dst []byte, r rune) []byte {
+	if !strings.ContainsRune(validCodes, d.code(r)) {
+		return d.verbatimText(dst, r)
+	}
+
+	d.startWord()
+	d.src = appendRune(d.src, r)
+	d.wordIn[1] = d.cur[1]
+	if !d.failed {
+		var err error
+		d.buffering = true
+		if d.word, err = d.add(d.word, r); err != nil {
+			d.failWord(err)
+		}
+		d.buffering = false
+	}
+	return dst
+}
+
+// startWord starts a word of RecoverVerbatim at the current rune, unless
+// one is pending.
+func (d *Decoder) startWord() {
+	if len(d.src) == 0 {
+		d.wordStats = d.stats
+		d.wordIn[0] = d.cur[0]
+		d.wordPos = d.curPos
+	}
+}
+
+// failWord marks the pending word of RecoverVerbatim as invalid by err,
+// unless it already is.
+func (d *Decoder) failWord(err error) {
+	if !d.failed {
+		d.failed = true
+		d.wordErr = err
+	}
+}
+
+// piece passes the piece of kind read from the input range in at pos, with
+// its text, to onPiece, if set.
+func (d *Decoder) piece(kind pieceKind, in [2]int, pos Position, text []byte) {
+	if d.onPiece != nil {
+		d.onPiece(piece{kind: kind, in: in, pos: pos, text: text})
+	}
+}
+
+// verbatimText is addText for RecoverVerbatim.
+func (d *Decoder) verbatimText(dst []byte, r rune) []byte {
+	d.buffering = true
+	if finalSigma(r) || d.Digits == DigitsCodes && unicode.IsDigit(r) {
+		d.word = d.endWord(d.word)
+	} else {
+		d.word = d.appendSym(d.word)
+	}
+	d.buffering = false
+	dst = d.flushWord(dst)
+	if d.onPiece != nil {
+		d.pieceText = appendRune(d.pieceText[:0], r)
+		d.piece(pieceText, d.cur, d.curPos, d.pieceText)
+	}
+	return d.appendMapped(dst, d.cur, r)
+}
+
+// finishWord appends the pending symbol and, with RecoverVerbatim, the
+// pending word as the end of a word.
+func (d *Decoder) finishWord(dst []byte) []byte {
+	if !d.verbatim() {
+		return d.endWord(dst)
+	}
+
+	d.buffering = true
+	d.word = d.endWord(d.word)
+	d.buffering = false
+	return d.flushWord(dst)
+}
+
+// flushWord appends the pending word, or its source if it is invalid.
+func (d *Decoder) flushWord(dst []byte) []byte {
+	n := len(dst)
+	if d.failed {
+		d.stats = d.wordStats
+		d.stats.Errors++
+		dst = append(dst, d.VerbatimOpen...)
+		dst = append(dst, d.src...)
+		dst = append(dst, d.VerbatimClose...)
+	} else {
+		dst = append(dst, d.word...)
+	}
+	if d.sink != nil {
+		dst = d.divert(dst, n)
+		if !d.failed {
+			for _, sym := range d.sinkWord {
+				d.sinkSym(sym)
+			}
+		}
+		if d.failed || len(d.sinkWord) > 0 {
+			d.sinkBoundary()
+		}
+		d.sinkWord = d.sinkWord[:0]
+	}
+	if d.dual {
+		if d.failed {
+			d.alt = append(d.alt, d.VerbatimOpen...)
+			d.alt = append(d.alt, d.src...)
+			d.alt = append(d.alt, d.VerbatimClose...)
+		} else {
+			d.alt = append(d.alt, d.altWord...)
+		}
+		d.altWord = d.altWord[:0]
+	}
+	d.mapped(d.wordIn, dst[n:], true)
+	if d.onPiece != nil && len(d.src) > 0 {
+		d.onPiece(piece{kind: pieceWord, in: d.wordIn, pos: d.wordPos, text: d.word, syms: d.wordSyms, err: d.wordErr})
+	}
+
+	d.word = d.word[:0]
+	d.src = d.src[:0]
+	d.failed = false
+	d.wordErr = nil
+	d.wordSyms = d.wordSyms[:0]
+	return dst
+}
+
+// endWord appends the pending symbol as the end of a word.
+func (d *Decoder) endWord(dst []byte) []byte {
+	// Set sigma to final variant.
+	if d.sym.Base == 's' && d.Sigma == SigmaAuto {
+		d.sym.Base = 'j'
+	}
+	dst = d.appendSym(dst)
+	if d.sink != nil && d.inWord && !d.buffering {
+		d.sinkBoundary()
+	}
+	d.inWord = false
+	d.prev = Sym{}
+	d.index = 0
+	return dst
+}
+
+// appendSym appends the pending symbol to dst and resets it.
+func (d *Decoder) appendSym(dst []byte) []byte {
+	if d.sym.Base == 0 && d.sym.ast {
+		d.warn(d.symPos, string(d.symSrc), "asterisk without letter dropped")
+	}
+	// Nothing to output, e.g. between two non-code runes.
+	if d.sym.Base != 0 {
+		d.stats.Symbols++
+		d.prev = d.sym
+		d.index++
+		if !d.inWord {
+			d.stats.Words++
+			d.inWord = true
+		}
+
+		n := len(dst)
+		sym, adscript := d.sym, false
+		if d.Caps {
+			sym, adscript = caps(sym)
+		}
+		if d.Plain {
+			sym.Accent, sym.Spiritus = 0, 0
+		}
+
+		if d.onPiece != nil && d.buffering {
+			d.wordSyms = append(d.wordSyms, sym)
+			if adscript {
+				d.wordSyms = append(d.wordSyms, Sym{Base: 'I'})
+			}
+		}
+		if d.sink != nil {
+			d.sinkSym(sym)
+			if adscript {
+				d.sinkSym(Sym{Base: 'I'})
+			}
+		} else {
+			dst = d.appendForm(dst, sym, adscript, d.Combining && !d.dual)
+			if d.VerifyNFC && d.normErr == nil && !singleChar(dst[n:], adscript) {
+				d.normErr = &NormError{Offset: d.symIn[0], Output: string(dst[n:]), Msg: "has no precomposed character"}
+			}
+			dst = append(dst, d.marks...)
+		}
+		if d.dual {
+			if d.buffering {
+				d.altWord = d.appendForm(d.altWord, sym, adscript, true)
+				d.altWord = append(d.altWord, d.marks...)
+			} else {
+				d.alt = d.appendForm(d.alt, sym, adscript, true)
+				d.alt = append(d.alt, d.marks...)
+			}
+		}
+		d.mapped(d.symIn, dst[n:], true)
+	}
+
+	d.sym.Reset()
+	d.symSrc = d.symSrc[:0]
+	d.lunate = false
+	d.marks = d.marks[:0]
+	return dst
+}
+
+// appendForm appends sym, with combining diacritics if combining, and an
+// adscript iota if adscript.
+func (d *Decoder) appendForm(dst []byte, sym Sym, adscript, combining bool) []byte {
+	switch {
+	case (d.Sigma == SigmaLunate || d.lunate) && (sym.Base == 's' || sym.Base == 'j'):
+		dst = appendRune(dst, '\u03F2')
+	case (d.Sigma == SigmaLunate || d.lunate) && sym.Base == 'S':
+		dst = appendRune(dst, '\u03F9')
+	case combining:
+		dst = sym.appendCombining(dst, d.Canonical)
+	default:
+		dst = append(dst, precombined(sym, d.Canonical)...)
+	}
+	if adscript {
+		dst = appendRune(dst, '\u0399')
+	}
+	return dst
+}
+
+// validate checks the symbol, which was old before r was added, against the
+// rules of the Level beyond those of Sym.Add.
+func (d *Decoder) validate(old Sym, r rune) error {
+	second := ""
+	switch {
+	case old.Accent != 0 && (r == '/' || r == '\\' || r == '='):
+		second = "second accent"
+	case old.Spiritus != 0 && (r == '(' || r == ')'):
+		second = "second breathing"
+	}
+	if d.Level < LevelStandard {
+		if second != "" {
+			d.warn(d.curPos, string(d.symSrc), second+" replaces the first")
+		}
+		return nil
+	}
+
+	if second != "" {
+		return errors.New("can't put " + second + " on symbol")
+	}
+	if err := strictErr(d.sym); err != nil {
+		return err
+	}
+
+	if d.Level >= LevelPedantic && d.sym.Spiritus != 0 && !d.initial() {
+		return errors.New("can't put breathing on non-initial vowel")
+	}
+	return nil
+}
+
+// initial reports whether sym may have a breathing: it is rho or the initial
+// vowel or diphthong of the word. Before its base, it might be anything.
+func (d *Decoder) initial() bool {
+	b := unicode.ToLower(d.sym.Base)
+	return b == 0 || b == 'r' || d.index == 0 || d.index == 1 && diphthong(d.prev, d.sym)
+}
+
+// caps returns sym as a capital without accent and breathing, and whether
+// it had an iota subscript, which is then written as a full iota.
+func caps(sym Sym) (Sym, bool) {
+	iota := sym.Iota
+	if sym.Base == 'j' {
+		sym.Base = 's'
+	}
+	sym.Base = unicode.ToUpper(sym.Base)
+	sym.Accent, sym.Spiritus, sym.Iota = 0, 0, false
+	return sym, iota
+}
+
+// finalSigma reports whether a sigma directly before r ends a word: r is
+// whitespace, punctuation (including apostrophes and closing quotes), a
+// symbol (including the spacing koronis ᾽), a control character or 0 for
+// the end of input. Hyphens, which join the halves of a word broken across
+// lines, and all other characters leave the sigma medial.
+func finalSigma(r rune) bool {
+	switch r {
+	case '-', '\u2010', '\u2011', '\u00AD':
+		return false
+	}
+
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsControl(r) || r == 0
+}
+
+func appendRune(dst []byte, r rune) []byte {
+	var b [utf8.UTFMax]byte
+	n := utf8.EncodeRune(b[:], r)
+	return append(dst, b[:n]...)
+}
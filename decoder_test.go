@@ -0,0 +1,717 @@
+package beta
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestDecoder(t *testing.T) {
+	var d Decoder
+	var got []string
+
+	for _, r := range "lo/gos a)=" {
+		emit, err := d.Push(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, emit)
+	}
+	emit, _ := d.Flush()
+	got = append(got, emit)
+
+	ref := []string{"", "λ", "", "ό", "γ", "ο", "ς ", "", "", "", "ἆ"}
+	if len(got) != len(ref) {
+		t.Fatalf("expected %q, got %q", ref, got)
+	}
+	for i := range ref {
+		if got[i] != ref[i] {
+			t.Errorf("expected %q, got %q", ref, got)
+			break
+		}
+	}
+
+	d.Reset()
+	d.Push('k')
+	if _, err := d.Push('/'); err == nil {
+		t.Error("expected error for accent on consonant")
+	}
+	if emit, err := d.Push('a'); err != nil || emit != "" {
+		t.Error("expected Decoder to recover after error, got", emit, err)
+	}
+}
+
+func TestDecoderResetMidWord(t *testing.T) {
+	var words, maps []Mapping
+	opts := Options{
+		Recovery: RecoverVerbatim,
+		OnWord:   func(greek string, m Mapping) { words = append(words, m) },
+		Map:      func(m Mapping) { maps = append(maps, m) },
+	}
+	run := func(d *Decoder, src string) string {
+		words, maps = nil, nil
+		var out string
+		for _, r := range src {
+			emit, err := d.Push(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out += emit
+		}
+		emit, err := d.Flush()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out + emit
+	}
+
+	const src = "kai\\ k/ lo/gos"
+	out := run(&Decoder{Options: opts}, src)
+	refWords, refMaps := words, maps
+
+	for _, prev := range []string{"qea/ lo/g", "qea/ lo/gk/", "qea/ *)", "qea/ {Llo/g"} {
+		d := Decoder{Options: opts}
+		for _, r := range prev {
+			if _, err := d.Push(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		d.Reset()
+
+		if got := run(&d, src); got != out {
+			t.Errorf("after %q: expected %q, got %q", prev, out, got)
+		}
+		if !reflect.DeepEqual(words, refWords) || !reflect.DeepEqual(maps, refMaps) {
+			t.Errorf("after %q: expected words %v and maps %v, got %v and %v", prev, refWords, refMaps, words, maps)
+		}
+	}
+}
+
+func TestFinalSigma(t *testing.T) {
+	tests := []struct {
+		beta, greek string
+	}{
+		{"lo/gos' kai/", "λόγος' καί"},
+		{"\"lo/gos\"", "\"λόγος\""},
+		{"lo/gos’", "λόγος’"},
+		{"lo/gos᾽", "λόγος᾽"},
+		{"lo/gos»", "λόγος»"},
+		{"*)as-\nklhpio/s", "Ἀσ-\nκληπιός"},
+		{"lo/gos", "λόγος"},
+	}
+
+	for _, tt := range tests {
+		s, err := ToGreek(tt.beta)
+		if err != nil {
+			t.Error(err)
+		} else if s != tt.greek {
+			t.Errorf("%q: expected %q, got %q", tt.beta, tt.greek, s)
+		}
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	tests := []struct {
+		opts        Options
+		beta, greek string
+	}{
+		{Options{}, "lo/gos {Lsee p. 5L} qea/", "λόγος see p. 5 θεά"},
+		{Options{}, "lo/gos{LxL}", "λόγοςx"},
+		{Options{}, "{lo/gos} L", "{λόγος} Λ"},
+		{Options{LiteralOpen: "<<", LiteralClose: ">>"}, "qea/ <<god>> {LxL}", "θεά god {ΛχΛ}"},
+	}
+
+	for _, tt := range tests {
+		var sb strings.Builder
+		w := NewWriter(&sb)
+		w.Options = tt.opts
+		w.Write([]byte(tt.beta))
+		w.Flush()
+
+		if sb.String() != tt.greek {
+			t.Errorf("%q: expected %q, got %q", tt.beta, tt.greek, sb.String())
+		}
+	}
+}
+
+func TestRecoverVerbatim(t *testing.T) {
+	tests := []struct {
+		opts        Options
+		beta, greek string
+	}{
+		{Options{Recovery: RecoverVerbatim}, "qea/ k/ai lo/gos", "θεά k/ai λόγος"},
+		{Options{Recovery: RecoverVerbatim, VerbatimOpen: "[", VerbatimClose: "]"}, "k/ai, lo/gos k/", "[k/ai], λόγος [k/]"},
+		{Options{Recovery: RecoverVerbatim}, "k/{LxL}", "k/x"},
+	}
+
+	for _, tt := range tests {
+		var sb strings.Builder
+		w := NewWriter(&sb)
+		w.Options = tt.opts
+		if _, err := w.Write([]byte(tt.beta)); err != nil {
+			t.Error(err)
+		}
+		if err := w.Flush(); err != nil {
+			t.Error(err)
+		}
+
+		if sb.String() != tt.greek {
+			t.Errorf("%q: expected %q, got %q", tt.beta, tt.greek, sb.String())
+		}
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		action Action
+		greek  string
+		abort  bool
+	}{
+		{Skip, "θεά αι", false},
+		{Replace, "θεά \uFFFDαι", false},
+		{Abort, "θεά ", true},
+	}
+
+	for _, tt := range tests {
+		var pos Position
+		var source string
+		d := Decoder{Options: Options{Handler: func(err error, p Position, src string) Action {
+			pos, source = p, src
+			return tt.action
+		}}}
+
+		s, err := d.convert(nil, "qea/ k/ai")
+		if string(s) != tt.greek {
+			t.Errorf("%d: expected %q, got %q", tt.action, tt.greek, s)
+		}
+		if (err != nil) != tt.abort {
+			t.Errorf("%d: unexpected error %v", tt.action, err)
+		}
+		if pos.String() != "1:7" || source != "k/" {
+			t.Errorf("%d: expected source \"k/\" at 1:7, got %q at %s", tt.action, source, pos)
+		}
+	}
+}
+
+func TestReplacement(t *testing.T) {
+	replace := func(error, Position, string) Action { return Replace }
+	tests := []struct {
+		opts  Options
+		greek string
+	}{
+		{Options{Replacement: '?'}, "θεά ?αι"},
+		{Options{Replacement: -1}, "θεά αι"},
+		{Options{ReplaceOpen: "\u27E6", ReplaceClose: "\u27E7"}, "θεά \uFFFD\u27E6k/\u27E7αι"},
+		{Options{Replacement: -1, ReplaceOpen: "<", ReplaceClose: ">"}, "θεά <k/>αι"},
+	}
+
+	for _, tt := range tests {
+		d := Decoder{Options: tt.opts}
+		d.Handler = replace
+		s, err := d.convert(nil, "qea/ k/ai")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(s) != tt.greek {
+			t.Errorf("expected %q, got %q", tt.greek, s)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		opts        Options
+		beta, greek string
+	}{
+		{Options{Canonical: true}, "i/+", "\u0390"},
+		{Options{Canonical: true, Combining: true}, "i/+", "\u03B9\u0308\u0301"},
+		{Options{Canonical: true, Combining: true}, "a)/|", "\u03B1\u0313\u0301\u0345"},
+		{Options{Combining: true}, "i/+", "\u03B9\u0301\u0308"},
+	}
+
+	for _, tt := range tests {
+		d := Decoder{Options: tt.opts}
+		s, err := d.convert(nil, tt.beta)
+		if err != nil {
+			t.Error(err)
+		} else if string(s) != tt.greek {
+			t.Errorf("%q: expected %q, got %q", tt.beta, tt.greek, s)
+		}
+		if tt.opts.Combining && tt.opts.Canonical && !norm.NFD.IsNormalString(string(s)) {
+			t.Errorf("%q: output %q is not in NFD", tt.beta, s)
+		}
+	}
+}
+
+func TestCaps(t *testing.T) {
+	tests := []struct {
+		opts        Options
+		beta, greek string
+	}{
+		{Options{Caps: true}, "*)odusseu/s", "\u039F\u0394\u03A5\u03A3\u03A3\u0395\u03A5\u03A3"},
+		{Options{Caps: true}, "w)|dh=| Phlhi+a/dew", "\u03A9\u0399\u0394\u0397\u0399 \u03A0\u0397\u039B\u0397\u03AA\u0391\u0394\u0395\u03A9"},
+		{Options{Caps: true}, "lo/gos", "\u039B\u039F\u0393\u039F\u03A3"},
+		{Options{Caps: true, Combining: true}, "i+", "\u0399\u0308"},
+		{Options{Caps: true, Sigma: SigmaLunate}, "lo/gos", "\u039B\u039F\u0393\u039F\u03F9"},
+	}
+
+	for _, tt := range tests {
+		d := Decoder{Options: tt.opts}
+		s, err := d.convert(nil, tt.beta)
+		if err != nil {
+			t.Error(err)
+		} else if string(s) != tt.greek {
+			t.Errorf("%q: expected %q, got %q", tt.beta, tt.greek, s)
+		}
+	}
+}
+
+func TestLevelStandard(t *testing.T) {
+	tests := []struct {
+		beta string
+		ok   bool
+	}{
+		{"mh=nin", true},
+		{"lo=gos", false},
+		{"e=", false},
+		{"*=e", false},
+		{"lo/gos", true},
+		{"w)|dh=|", true},
+		{"o|", false},
+		{"*e|", false},
+		{"Phlhi+a/dew", true},
+		{"a+", false},
+		{"a//", false},
+		{"a)(", false},
+		{"*)a(", false},
+	}
+
+	for _, tt := range tests {
+		d := Decoder{Options: Options{Level: LevelStandard}}
+		_, err := d.convert(nil, tt.beta)
+		if (err == nil) != tt.ok {
+			t.Errorf("%q: unexpected error %v", tt.beta, err)
+		}
+
+		if _, err := ToGreek(tt.beta); err != nil {
+			t.Errorf("%q: unexpected error %v at LevelPermissive", tt.beta, err)
+		}
+	}
+}
+
+func TestLevelPedantic(t *testing.T) {
+	tests := []struct {
+		beta string
+		ok   bool
+	}{
+		{"a)/eide", true},
+		{"ai)dw/s", true},
+		{"*(/hra", true},
+		{"r(h/twr", true},
+		{"lo/go(s", false},
+		{"ka)gw/", false},
+		{"ea)/n", false},
+		{"qea/ a)/eide", true},
+		{"lo=gos", false},
+	}
+
+	for _, tt := range tests {
+		d := Decoder{Options: Options{Level: LevelPedantic}}
+		_, err := d.convert(nil, tt.beta)
+		if (err == nil) != tt.ok {
+			t.Errorf("%q: unexpected error %v", tt.beta, err)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, l := range []Level{LevelPermissive, LevelStandard, LevelPedantic} {
+		if p, err := ParseLevel(l.String()); err != nil || p != l {
+			t.Errorf("ParseLevel(%q): got %v, %v", l, p, err)
+		}
+	}
+	if _, err := ParseLevel("lax"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		opts Options
+		beta string
+	}{
+		{Options{}, "qea/ {Lp. 5L} a)/ner"},
+		{Options{Combining: true, Caps: true}, "w)|dh=| lo/gos"},
+		{Options{Recovery: RecoverVerbatim, VerbatimOpen: "["}, "qea/ k/ai lo/gos"},
+		{Options{Handler: func(error, Position, string) Action { return Replace }}, "qea/ k/ai"},
+		{Options{Markers: MarkersKeep}, "lo/gos2 qea/[12]"},
+		{Options{Asterisk: AsteriskStrict, Handler: func(error, Position, string) Action { return Replace }}, "qea/ * a *"},
+	}
+
+	for _, tt := range tests {
+		var maps []Mapping
+		opts := tt.opts
+		opts.Map = func(m Mapping) {
+			maps = append(maps, m)
+		}
+
+		d := Decoder{Options: opts}
+		out, err := d.convert(nil, tt.beta)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The pieces of output are contiguous and in input order.
+		end, in := 0, 0
+		for _, m := range maps {
+			if m.OutStart != end || m.InStart < in || m.InEnd <= m.InStart {
+				t.Errorf("%q: mapping %v out of order", tt.beta, m)
+			}
+			end, in = m.OutEnd, m.InEnd
+		}
+		if end != len(out) {
+			t.Errorf("%q: mappings end at %d, output at %d", tt.beta, end, len(out))
+		}
+	}
+
+	_, maps, _ := ToGreekMap("lo/gos")
+	if len(maps) != 5 || maps[1] != (Mapping{1, 3, 2, 4}) {
+		t.Errorf("unexpected mappings %v", maps)
+	}
+}
+
+func TestMarkers(t *testing.T) {
+	tests := []struct {
+		policy MarkerPolicy
+		beta   string
+		greek  string
+	}{
+		{MarkersText, "lo/gos2 a", "λόγοσ2 α"},
+		{MarkersKeep, "lo/gos2 a", "λόγος2 α"},
+		{MarkersStrip, "lo/gos2 a[12]", "λόγος α"},
+		{MarkersStrip, "lo/g[os] 12", "λόγ[ος] 12"},
+	}
+
+	for _, tt := range tests {
+		var markers []Marker
+		d := Decoder{Options: Options{Markers: tt.policy, Marker: func(m Marker) {
+			markers = append(markers, m)
+		}}}
+
+		out, err := d.convert(nil, tt.beta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != tt.greek {
+			t.Errorf("%q: expected %q, got %q", tt.beta, tt.greek, out)
+		}
+		if tt.policy == MarkersText && markers != nil {
+			t.Errorf("%q: unexpected markers %v", tt.beta, markers)
+		}
+	}
+
+	// Markers split across Writes are recognised as well.
+	var markers []Marker
+	var buf strings.Builder
+	w := NewWriter(&buf)
+	w.Markers = MarkersStrip
+	w.Marker = func(m Marker) { markers = append(markers, m) }
+	w.WriteString("lo/gos[1")
+	w.WriteString("2] qea/")
+	w.Flush()
+
+	if buf.String() != "λόγος θεά" {
+		t.Errorf("unexpected %q", buf.String())
+	}
+	if len(markers) != 1 || markers[0].Text != "[12]" || markers[0].Pos.Col != 7 || markers[0].Out != 10 {
+		t.Errorf("unexpected markers %v", markers)
+	}
+}
+
+func TestAsterisk(t *testing.T) {
+	replace := func(error, Position, string) Action { return Replace }
+	tests := []struct {
+		opts  Options
+		beta  string
+		greek string
+		err   bool
+	}{
+		{Options{}, "* *a", " Α", false},
+		{Options{}, "a *", "α ", true},
+		{Options{Asterisk: AsteriskStrict}, "* a", "", true},
+		{Options{Asterisk: AsteriskStrict, Handler: replace}, "*, *)a *", "\uFFFD, \u1F08 \uFFFD", false},
+		{Options{Asterisk: AsteriskStrict, Recovery: RecoverVerbatim}, "*1 qea/", "*1 θεά", false},
+		{Options{Asterisk: AsteriskIgnore}, "*a a*", "α α", false},
+	}
+
+	for _, tt := range tests {
+		d := Decoder{Options: tt.opts}
+		s, err := d.convert(nil, tt.beta)
+		if string(s) != tt.greek {
+			t.Errorf("%q: expected %q, got %q", tt.beta, tt.greek, s)
+		}
+		if (err != nil) != tt.err {
+			t.Errorf("%q: unexpected error %v", tt.beta, err)
+		}
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	var d Decoder
+	for _, r := range "qea/ *)" {
+		if _, err := d.Push(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := d.Flush()
+	ierr, ok := err.(*IncompleteError)
+	if !ok {
+		t.Fatalf("expected IncompleteError, got %v", err)
+	}
+	if ierr.Source != "*)" || ierr.Pos.String() != "1:6" {
+		t.Errorf("expected \"*)\" at 1:6, got %q at %s", ierr.Source, ierr.Pos)
+	}
+
+	var serr *SyntaxError
+	if !errors.As(err, &serr) || serr.Msg != "asterisk without base character" {
+		t.Errorf("expected a SyntaxError in %v", err)
+	}
+}
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	tests := []struct {
+		src        string
+		pos        Position
+		incomplete bool
+	}{
+		{"k/", Position{Offset: 1, Line: 1, Col: 2}, false},
+		{"lo/gos k/ai", Position{Offset: 8, Line: 1, Col: 9}, false},
+		{"lo/gos\nkai\\ k/", Position{Offset: 13, Line: 2, Col: 7}, false},
+		{"\u00BBk/", Position{Offset: 3, Line: 1, Col: 3}, false},
+		{"qea/ *)", Position{Offset: 5, Line: 1, Col: 6}, true},
+		{"lo/gos\n\t*)", Position{Offset: 8, Line: 2, Col: 2}, true},
+	}
+
+	for _, tt := range tests {
+		_, err := ConvertAppend(nil, []byte(tt.src), Options{})
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("%q: expected a SyntaxError, got %v", tt.src, err)
+			continue
+		}
+		if serr.Pos != tt.pos {
+			t.Errorf("%q: expected %+v, got %+v", tt.src, tt.pos, serr.Pos)
+		}
+		var ierr *IncompleteError
+		if errors.As(err, &ierr) != tt.incomplete {
+			t.Errorf("%q: unexpected %T", tt.src, err)
+		}
+	}
+}
+
+func TestDialectTLG(t *testing.T) {
+	d := Decoder{Options: Options{Dialect: DialectTLG}}
+	s, err := d.convert(nil, "MH=NIN A)/EIDE QEA/ *PHLHI+A/DEW *)AXILH=OS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(s) != "μῆνιν ἄειδε θεά Πηληϊάδεω Ἀχιλῆος" {
+		t.Errorf("unexpected %q", s)
+	}
+}
+
+func TestIgnoreCase(t *testing.T) {
+	d := Decoder{Options: Options{IgnoreCase: true}}
+	s, err := d.convert(nil, "Mh=nin A)/eide qea/, *phlhi+a/dew *)Axilh=os")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(s) != "μῆνιν ἄειδε θεά, Πηληϊάδεω Ἀχιλῆος" {
+		t.Errorf("unexpected %q", s)
+	}
+}
+
+func TestEscapes(t *testing.T) {
+	tests := []struct {
+		beta, greek string
+	}{
+		{"qea/ &#x3B1;&#945; \\u03b1", "θεά αα α"},
+		{"&#97;)/ &#38;#97;", "\u1F04 &#97;"},
+		{"a\\ &# &#; &#x;", "\u1F70 &# &#; &#\u03C7;"},
+		{"&#x110000;", "&#\u03C7110000;"},
+	}
+
+	for _, tt := range tests {
+		d := Decoder{Options: Options{Escapes: true}}
+		s, err := d.convert(nil, tt.beta)
+		if err != nil {
+			t.Errorf("%q: %v", tt.beta, err)
+		} else if string(s) != tt.greek {
+			t.Errorf("%q: expected %q, got %q", tt.beta, tt.greek, s)
+		}
+	}
+
+	// An escape split across Writes is recognised, and the mappings
+	// cover all of it.
+	var maps []Mapping
+	var sb strings.Builder
+	w := NewWriter(&sb)
+	w.Escapes = true
+	w.Map = func(m Mapping) { maps = append(maps, m) }
+	w.WriteString("a &#x3")
+	w.WriteString("B1; b")
+	w.Flush()
+	if sb.String() != "α α β" {
+		t.Errorf("unexpected %q", sb.String())
+	}
+	if len(maps) != 5 || maps[2] != (Mapping{2, 9, 3, 5}) {
+		t.Errorf("unexpected mappings %v", maps)
+	}
+}
+
+func TestDigits(t *testing.T) {
+	tests := []struct {
+		beta, greek string
+	}{
+		{"lo/gos1 lo/gos2 lo/gos3", "λόγοσ λόγος λόγοϲ"},
+		{"*s3 *s2", "Ϲ Σ"},
+		{"s2a", "ςα"},
+		{"[1qea/]1 [4a]4", "(θεά) ⟦α⟧"},
+		{"ti/%1 %", "τί? †"},
+		{"#2 #", "ϛ ʹ"},
+		{`"1a)/"2`, "\u201E\u1F04\u201C"},
+		{"%12 os2", "%12 ος"},
+		{"as4", "ας4"},
+	}
+
+	for _, tt := range tests {
+		d := Decoder{Options: Options{Digits: DigitsCodes}}
+		s, err := d.convert(nil, tt.beta)
+		if err != nil {
+			t.Errorf("%q: %v", tt.beta, err)
+		} else if string(s) != tt.greek {
+			t.Errorf("%q: expected %q, got %q", tt.beta, tt.greek, s)
+		}
+	}
+
+	// The Standard Betacode of FromGreekDialect is read back.
+	const greek = "Ὅμηροσ: Μῆνιν"
+	src, err := FromGreekDialect(greek, DialectStandard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Decoder{Options: Options{Digits: DigitsCodes}}
+	if s, err := d.convert(nil, src); err != nil || string(s) != greek {
+		t.Errorf("%q: expected %q, got %q (%v)", src, greek, s, err)
+	}
+
+	// Codes split across Writes are recognised, with RecoverVerbatim too.
+	var sb strings.Builder
+	w := NewWriter(&sb)
+	w.Digits = DigitsCodes
+	w.Recovery = RecoverVerbatim
+	w.WriteString("los")
+	w.WriteString("1 [")
+	w.WriteString("1k/]1")
+	w.Flush()
+	if sb.String() != "λοσ (k/)" {
+		t.Errorf("unexpected %q", sb.String())
+	}
+}
+
+func TestCapitalDiphthongs(t *testing.T) {
+	// The marks of a diphthong after a capital are on the second vowel in
+	// every dialect.
+	tests := []struct {
+		dialect Dialect
+		beta    string
+	}{
+		{DialectTypeGreek, "Ai(/mwn"},
+		{DialectStandard, "*ai(/mwn"},
+		{DialectTLG, "*AI(/MWN"},
+	}
+
+	for _, tt := range tests {
+		d := Decoder{Options: Options{Dialect: tt.dialect, Level: LevelPedantic}}
+		s, err := d.convert(nil, tt.beta)
+		if err != nil {
+			t.Errorf("%q: %v", tt.beta, err)
+		} else if string(s) != "\u0391\u1F35\u03BC\u03C9\u03BD" {
+			t.Errorf("%q: unexpected %+q", tt.beta, s)
+		}
+	}
+}
+
+func TestVerifyNFC(t *testing.T) {
+	tests := []struct {
+		src       string
+		combining bool
+		offset    int // -1 if valid
+	}{
+		{"mh=nin a)/eide, qea/ {Lab\u00E9L}", false, -1},
+		{"h+ a", false, 0},        // No precomposed eta with diaeresis
+		{"la\u0301", false, 2},    // Combines with the alpha
+		{"{Le\u0301L}", false, 3}, // Combines with the e
+		{"a/", true, 0},
+	}
+
+	for _, tt := range tests {
+		out, err := ConvertAppend(nil, []byte(tt.src), Options{VerifyNFC: true, Combining: tt.combining})
+		var nerr *NormError
+		switch {
+		case tt.offset < 0 && err != nil:
+			t.Errorf("%q: unexpected error %v", tt.src, err)
+		case tt.offset >= 0 && (!errors.As(err, &nerr) || nerr.Offset != tt.offset):
+			t.Errorf("%q: expected NormError at %d, got %v", tt.src, tt.offset, err)
+		}
+		if len(out) == 0 {
+			t.Errorf("%q: expected output despite the error", tt.src)
+		}
+	}
+}
+
+func TestWarn(t *testing.T) {
+	tests := []struct {
+		src     string
+		dialect Dialect
+		warns   []string
+	}{
+		{"mh=nin a)/eide", DialectTypeGreek, nil},
+		{"a/\\ a)(", DialectTypeGreek, []string{
+			"1:3: second accent replaces the first: a/\\",
+			"1:7: second breathing replaces the first: a)(",
+		}},
+		{"* lo/goj", DialectStandard, []string{
+			"1:1: asterisk without letter dropped: *",
+			"1:8: j for final sigma is not Standard Betacode: j",
+		}},
+		{"lo/goj", DialectTypeGreek, nil},
+	}
+
+	for _, tt := range tests {
+		var warns []string
+		opts := Options{Dialect: tt.dialect, Warn: func(d Diagnostic) {
+			if d.Severity != Warning {
+				t.Errorf("%q: unexpected severity %s", tt.src, d.Severity)
+			}
+			warns = append(warns, d.String())
+		}}
+		if _, err := ConvertAppend(nil, []byte(tt.src), opts); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(warns, "\n") != strings.Join(tt.warns, "\n") {
+			t.Errorf("%q: expected %q, got %q", tt.src, tt.warns, warns)
+		}
+	}
+
+	// At LevelStandard, a second accent is an error instead.
+	var warned bool
+	_, err := ConvertAppend(nil, []byte("a/\\"), Options{Level: LevelStandard, Warn: func(Diagnostic) { warned = true }})
+	if err == nil || warned {
+		t.Errorf("expected an error and no warning, got %v, %v", err, warned)
+	}
+}
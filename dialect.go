@@ -0,0 +1,173 @@
+package beta
+
+import (
+	"errors"
+	"strconv"
+	"unicode"
+)
+
+// A Dialect is a flavour of Betacode.
+type Dialect int
+
+const (
+	// TypeGreek Betacode: capitals are capital letters, diacritics come
+	// after the letter, and final sigma is j or an s at the end of a word.
+	DialectTypeGreek Dialect = iota
+
+	// Standard Betacode: capitals are marked by an asterisk, followed
+	// by their diacritics and the letter, as in *)/a, and sigma is s1
+	// when medial at the end of a word and s2 when final.
+	DialectStandard
+
+	// Standard Betacode in capitals, as in the texts of the TLG: *)/A.
+	DialectTLG
+
+	// TypeGreek with the letters of the Greek keyboard layout, for those
+	// used to typing modern Greek: u is θ, y is υ, j is ξ, c is ψ, v is ω
+	// and w is final sigma. q, which is no letter there, is still θ.
+	DialectKeyboard
+)
+
+// A DialectTable describes a dialect for RegisterDialect by how it differs
+// from TypeGreek.
+type DialectTable struct {
+	// The codes of the dialect that differ, mapped to the TypeGreek codes
+	// they stand for, like 'u' to 'q' for a dialect with u for θ. Letters
+	// are mapped in either case; codes not in the table are read as in
+	// TypeGreek. Greek is encoded with the reverse mapping, so no two codes
+	// may stand for the same.
+	Codes map[rune]rune
+
+	// Capitals are marked by an asterisk, followed by their diacritics and
+	// the letter, as in Standard Betacode.
+	Asterisks bool
+
+	// Letters of either case are the same, and Betacode is written in
+	// capitals, as in the TLG.
+	Capitals bool
+}
+
+// A dialect is a registered Dialect.
+type dialect struct {
+	name string
+	DialectTable
+	keys map[rune]rune // Reverse of Codes
+}
+
+var dialects = []dialect{
+	{name: "typegreek"},
+	{name: "standard", DialectTable: DialectTable{Asterisks: true}},
+	{name: "tlg", DialectTable: DialectTable{Asterisks: true, Capitals: true}},
+	{
+		name:         "keyboard",
+		DialectTable: DialectTable{Codes: map[rune]rune{'u': 'q', 'y': 'u', 'j': 'c', 'c': 'y', 'v': 'w', 'w': 'j'}},
+		keys:         map[rune]rune{'q': 'u', 'u': 'y', 'c': 'j', 'y': 'c', 'w': 'v', 'j': 'w'},
+	},
+}
+
+// RegisterDialect adds the dialect described by table under name, which
+// ParseDialect and thus the -dialect flag of the beta command then accept,
+// and returns it. Decoders, Encoders, Writers and Readers work with it like
+// with the predefined dialects, but DetectDialect never returns it.
+// RegisterDialect is meant to be called from init functions; it must not
+// be called concurrently with conversions or itself.
+func RegisterDialect(name string, table DialectTable) (Dialect, error) {
+	if name == "" {
+		return 0, errors.New("empty dialect name")
+	}
+	if _, err := ParseDialect(name); err == nil {
+		return 0, errors.New("dialect " + strconv.Quote(name) + " already registered")
+	}
+
+	dl := dialect{name: name, DialectTable: table}
+	if len(table.Codes) > 0 {
+		dl.Codes = make(map[rune]rune, len(table.Codes))
+		dl.keys = make(map[rune]rune, len(table.Codes))
+		for c, to := range table.Codes {
+			c, to = unicode.ToLower(c), unicode.ToLower(to)
+			if _, ok := dl.keys[to]; ok {
+				return 0, errors.New("dialect " + strconv.Quote(name) + ": two codes for " + strconv.QuoteRune(to))
+			}
+			dl.Codes[c], dl.keys[to] = to, c
+		}
+	}
+
+	dialects = append(dialects, dl)
+	return Dialect(len(dialects) - 1), nil
+}
+
+// table returns the registered dialect of d; unknown dialects are read and
+// written as TypeGreek.
+func (d Dialect) table() *dialect {
+	if d < 0 || int(d) >= len(dialects) {
+		return &dialects[DialectTypeGreek]
+	}
+	return &dialects[d]
+}
+
+// asterisks reports whether capitals are marked by an asterisk.
+func (d Dialect) asterisks() bool {
+	return d.table().Asterisks
+}
+
+// remap returns the letter r, of either case, as by table, or r.
+func remap(r rune, table map[rune]rune) rune {
+	m, ok := table[unicode.ToLower(r)]
+	switch {
+	case !ok:
+		return r
+	case unicode.IsUpper(r):
+		return unicode.ToUpper(m)
+	}
+	return m
+}
+
+func (d Dialect) String() string {
+	if d < 0 || int(d) >= len(dialects) {
+		return "Dialect(" + strconv.Itoa(int(d)) + ")"
+	}
+	return dialects[d].name
+}
+
+// ParseDialect returns the Dialect of the given name, as returned by String.
+func ParseDialect(name string) (Dialect, error) {
+	for i, dl := range dialects {
+		if dl.name == name {
+			return Dialect(i), nil
+		}
+	}
+	return 0, errors.New("unknown dialect " + strconv.Quote(name))
+}
+
+// DetectDialect guesses the Dialect of a sample of Betacode: DialectTLG if
+// nearly all letters are capitals, DialectStandard if there are more
+// asterisks than capitals or the codes of Standard Betacode, like %, # or a
+// numbered sigma, and DialectTypeGreek otherwise.
+func DetectDialect(sample []byte) Dialect {
+	var letters, upper, asterisks, codes int
+	for i, c := range sample {
+		switch {
+		case 'a' <= c && c <= 'z':
+			letters++
+		case 'A' <= c && c <= 'Z':
+			letters++
+			upper++
+		case c == '*':
+			asterisks++
+		case c == '%' || c == '#':
+			codes++
+		case '1' <= c && c <= '3' && i > 0 && (sample[i-1] == 's' || sample[i-1] == 'S'):
+			codes++
+		}
+	}
+
+	switch {
+	case letters == 0:
+		return DialectTypeGreek
+	case upper*10 >= letters*9:
+		return DialectTLG
+	case asterisks > upper || codes > 0:
+		return DialectStandard
+	}
+	return DialectTypeGreek
+}
@@ -0,0 +1,110 @@
+package beta
+
+import "testing"
+
+func TestParseDialect(t *testing.T) {
+	for _, d := range []Dialect{DialectTypeGreek, DialectStandard, DialectTLG, DialectKeyboard} {
+		p, err := ParseDialect(d.String())
+		if err != nil || p != d {
+			t.Errorf("%s: got %v, %v", d, p, err)
+		}
+	}
+	if _, err := ParseDialect("perseus"); err == nil {
+		t.Error("expected error for unknown dialect")
+	}
+	if s := Dialect(7).String(); s != "Dialect(7)" {
+		t.Errorf("unexpected %q", s)
+	}
+}
+
+func TestDetectDialect(t *testing.T) {
+	tests := []struct {
+		sample  string
+		dialect Dialect
+	}{
+		{"Mh=nin a)/eide qea/ Phlhi+a/dew A)xilh=os", DialectTypeGreek},
+		{"mh=nin a)/eide qea/ *phlhi+a/dew *)axilh=os", DialectStandard},
+		{"lo/gos1 a)/eide qea/ %5", DialectStandard},
+		{"MH=NIN A)/EIDE QEA/ *PHLHI+A/DEW *)AXILH=OS", DialectTLG},
+		{"", DialectTypeGreek},
+	}
+
+	for _, tt := range tests {
+		if d := DetectDialect([]byte(tt.sample)); d != tt.dialect {
+			t.Errorf("%q: expected %s, got %s", tt.sample, tt.dialect, d)
+		}
+	}
+}
+
+func TestDialectKeyboard(t *testing.T) {
+	out, err := ConvertAppend(nil, []byte("cyxh/ uea/ je/nos Vkeanow"), Options{Dialect: DialectKeyboard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	greek := "\u03C8\u03C5\u03C7\u03AE \u03B8\u03B5\u03AC \u03BE\u03AD\u03BD\u03BF\u03C2 \u03A9\u03BA\u03B5\u03B1\u03BD\u03BF\u03C2"
+	if string(out) != greek {
+		t.Errorf("expected %q, got %q", greek, out)
+	}
+
+	beta, err := FromGreekDialect(greek, DialectKeyboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if beta != "cyxh/ uea/ je/nos Vkeanos" {
+		t.Errorf("unexpected %q", beta)
+	}
+}
+
+func TestRegisterDialect(t *testing.T) {
+	n := len(dialects)
+	t.Cleanup(func() { dialects = dialects[:n] })
+
+	d, err := RegisterDialect("test", DialectTable{
+		Codes:     map[rune]rune{'y': 'u', '\u0113': 'h', '\u014D': 'w'},
+		Asterisks: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := ParseDialect("test"); err != nil || p != d || d.String() != "test" {
+		t.Errorf("got %v, %v for %s", p, err, d)
+	}
+
+	beta := "*)/\u0113lios2 ty/x\u0113 \u0113)=\u014Dn"
+	greek := "\u1F2C\u03BB\u03B9\u03BF\u03C2 \u03C4\u03CD\u03C7\u03B7 \u1F26\u03C9\u03BD"
+	out, err := ConvertAppend(nil, []byte(beta), Options{Dialect: d, Digits: DigitsCodes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != greek {
+		t.Errorf("expected %q, got %q", greek, out)
+	}
+	if s, err := FromGreekDialect(greek, d); err != nil || s != beta {
+		t.Errorf("expected %q, got %q, %v", beta, s, err)
+	}
+
+	// The codes are remapped before anything else looks at them.
+	opts := Options{Dialect: d, Digits: DigitsCodes, Recovery: RecoverVerbatim}
+	out, err = ConvertAppend(nil, []byte("ty/x\u0113 k/\u0113"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "\u03C4\u03CD\u03C7\u03B7 k/\u0113"; string(out) != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+	sigma, err := RegisterDialect("test-sigma", DialectTable{Codes: map[rune]rune{'\u03C3': 's'}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = ConvertAppend(nil, []byte("lo/go\u03C31"), Options{Dialect: sigma, Digits: DigitsCodes})
+	if want := "\u03BB\u03CC\u03B3\u03BF\u03C3"; err != nil || string(out) != want {
+		t.Errorf("expected %q, got %q, %v", want, out, err)
+	}
+
+	if _, err := RegisterDialect("test", DialectTable{}); err == nil {
+		t.Error("expected error for a name registered twice")
+	}
+	if _, err := RegisterDialect("test2", DialectTable{Codes: map[rune]rune{'y': 'u', 'v': 'u'}}); err == nil {
+		t.Error("expected error for two codes of u")
+	}
+}
@@ -0,0 +1,169 @@
+package beta
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// A DiffKind classifies a Difference.
+type DiffKind int
+
+const (
+	DiffAccent    DiffKind = iota // Only accents differ
+	DiffBreathing                 // Breathings differ, and maybe accents
+	DiffLexical                   // Letters, iota subscripts or diaereses differ, or the word is missing
+)
+
+var diffKindNames = []string{"accent", "breathing", "lexical"}
+
+func (k DiffKind) String() string {
+	if k < 0 || int(k) >= len(diffKindNames) {
+		return "DiffKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return diffKindNames[k]
+}
+
+// A Difference is a word that differs between two texts.
+type Difference struct {
+	Kind DiffKind
+	A, B [2]int // Byte ranges of the word in each text; empty where it is missing
+}
+
+// A diffWord is a word of a text compared by Diff.
+type diffWord struct {
+	r    [2]int
+	syms []Sym
+	key  string // Letters, iota subscripts and diaereses
+}
+
+// Diff compares two texts, each Betacode or Greek, word by word and symbol by
+// symbol, for collating transcriptions of the same edition. Words that differ
+// in accents or breathings only are reported as such; other differences, and
+// words missing in one text among the next few words, are lexical. Final
+// sigma, normalization and oxia versus tonos don't count. Greek words with
+// letters of other scripts are ignored. Diff fails if Betacode does not
+// parse.
+func Diff(a, b string) ([]Difference, error) {
+	wa, err := diffWords(a)
+	if err != nil {
+		return nil, err
+	}
+	wb, err := diffWords(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []Difference
+	at := func(words []diffWord, i int, text string) [2]int {
+		if i < len(words) {
+			return [2]int{words[i].r[0], words[i].r[0]}
+		}
+		return [2]int{len(text), len(text)}
+	}
+
+	i, j := 0, 0
+	for i < len(wa) || j < len(wb) {
+		if i < len(wa) && j < len(wb) && wa[i].key == wb[j].key {
+			if kind, ok := diacriticDiff(wa[i].syms, wb[j].syms); ok {
+				diffs = append(diffs, Difference{kind, wa[i].r, wb[j].r})
+			}
+			i++
+			j++
+			continue
+		}
+
+		// Skip the words missing on one side, or else a changed word.
+		di, dj := 1, 1
+		switch {
+		case i >= len(wa):
+			di = 0
+		case j >= len(wb):
+			dj = 0
+		default:
+			for d := 1; d <= alignWindow; d++ {
+				if i+d < len(wa) && wa[i+d].key == wb[j].key {
+					di, dj = d, 0
+					break
+				}
+				if j+d < len(wb) && wa[i].key == wb[j+d].key {
+					di, dj = 0, d
+					break
+				}
+			}
+		}
+
+		switch {
+		case di == 1 && dj == 1:
+			diffs = append(diffs, Difference{DiffLexical, wa[i].r, wb[j].r})
+		case dj == 0:
+			for k := i; k < i+di; k++ {
+				diffs = append(diffs, Difference{DiffLexical, wa[k].r, at(wb, j, b)})
+			}
+		default:
+			for k := j; k < j+dj; k++ {
+				diffs = append(diffs, Difference{DiffLexical, at(wa, i, a), wb[k].r})
+			}
+		}
+		i += di
+		j += dj
+	}
+
+	return diffs, nil
+}
+
+// diacriticDiff returns the kind of difference of the symbols of two words
+// with the same key, if they differ.
+func diacriticDiff(a, b []Sym) (kind DiffKind, ok bool) {
+	for k := range a {
+		switch {
+		case a[k].Spiritus != b[k].Spiritus:
+			return DiffBreathing, true
+		case a[k].Accent != b[k].Accent:
+			kind, ok = DiffAccent, true
+		}
+	}
+	return kind, ok
+}
+
+// diffWords returns the words of text, which is Greek if it has Greek
+// letters and Betacode otherwise.
+func diffWords(text string) ([]diffWord, error) {
+	var words []diffWord
+	add := func(r [2]int, syms []Sym) {
+		var sb strings.Builder
+		for _, sym := range syms {
+			if sym.Base == 'j' {
+				sym.Base = 's'
+			}
+			sb.WriteString(Sym{Base: sym.Base, Iota: sym.Iota, Trema: sym.Trema}.String())
+		}
+		words = append(words, diffWord{r, syms, sb.String()})
+	}
+
+	if strings.IndexFunc(text, func(r rune) bool { return unicode.Is(unicode.Greek, r) }) < 0 {
+		ws, err := Words(text)
+		for _, w := range ws {
+			add([2]int{w.Pos.Offset, w.Pos.Offset + len(w.Source)}, w.Syms)
+		}
+		return words, err
+	}
+
+words:
+	for _, r := range greekWords(text) {
+		var syms []Sym
+		for s := text[r[0]:r[1]]; len(s) > 0; {
+			n := clusterLen(s)
+			sym, ok, err := clusterSym(norm.NFD.String(s[:n]))
+			if !ok || err != nil {
+				continue words
+			}
+			syms = append(syms, sym)
+			s = s[n:]
+		}
+		add(r, syms)
+	}
+	return words, nil
+}
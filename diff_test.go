@@ -0,0 +1,41 @@
+package beta
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	a := "mh=nin a)/eide qea/ Phlhi+a/dew A)xilh=os"
+	b := "mh=nin a(/eide qea\\ Phlhi+a/dou e)/ti A)xilh=os"
+	diffs, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref := []struct {
+		kind DiffKind
+		a, b string
+	}{
+		{DiffBreathing, "a)/eide", "a(/eide"},
+		{DiffAccent, "qea/", "qea\\"},
+		{DiffLexical, "Phlhi+a/dew", "Phlhi+a/dou"},
+		{DiffLexical, "", "e)/ti"},
+	}
+	if len(diffs) != len(ref) {
+		t.Fatalf("expected %d differences, got %v", len(ref), diffs)
+	}
+	for i, d := range diffs {
+		da, db := a[d.A[0]:d.A[1]], b[d.B[0]:d.B[1]]
+		if d.Kind != ref[i].kind || da != ref[i].a || db != ref[i].b {
+			t.Errorf("expected %s %q %q, got %s %q %q", ref[i].kind, ref[i].a, ref[i].b, d.Kind, da, db)
+		}
+	}
+
+	// Greek against Betacode, with final sigma and tonos.
+	diffs, err = Diff("lo/gos qeo/s", "\u03BB\u03CC\u03B3\u03BF\u03C2 \u03B8\u03B5\u1F79\u03C2")
+	if err != nil || len(diffs) != 0 {
+		t.Errorf("expected no differences, got %v, %v", diffs, err)
+	}
+
+	if _, err := Diff("k/", ""); err == nil {
+		t.Error("expected error for invalid Betacode")
+	}
+}
@@ -0,0 +1,153 @@
+package beta
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Document is Betacode source together with its conversion, which
+// Reconvert keeps up to date as the source is edited. It converts only the
+// lines from the one of an edit up to the first line after it at which the
+// conversion is the same as before, so a live preview of a large file stays
+// cheap.
+type Document struct {
+	opts  Options
+	src   string
+	greek []byte
+	lines []checkpoint // At the start of each line, ascending
+}
+
+// A checkpoint is the parser state at a line start.
+type checkpoint struct {
+	src   int // Offset in the source
+	out   int // Offset in the Greek
+	state State
+}
+
+// A Patch is the change of the Greek of a Document by an edit: the bytes
+// from Start to End of the old Greek are replaced by Greek. Offsets of the
+// old Greek from End on move by len(Greek) - (End - Start).
+type Patch struct {
+	Start, End int
+	Greek      string
+}
+
+// NewDocument converts the Betacode src with opts. On error, the output
+// up to it is returned with the error, which Reconvert returns as well
+// until the error is edited away; for a preview of invalid Betacode, use
+// Options.Handler or Options.Recovery.
+func NewDocument(src string, opts Options) (*Document, error) {
+	doc := &Document{opts: opts, src: src}
+	var err error
+	doc.greek, doc.lines, _, err = doc.convert(src, checkpoint{}, nil)
+	return doc, err
+}
+
+// Source returns the Betacode source.
+func (doc *Document) Source() string {
+	return doc.src
+}
+
+// Greek returns the conversion of the source.
+func (doc *Document) Greek() string {
+	return string(doc.greek)
+}
+
+// Reconvert replaces the source from start to end, which are byte offsets,
+// by text and returns the Patch of the Greek. On a conversion error, the
+// Document is edited and converted up to the error nonetheless, so that
+// the next edit can correct it.
+func (doc *Document) Reconvert(start, end int, text string) (Patch, error) {
+	if start < 0 || start > end || end > len(doc.src) {
+		return Patch{}, errors.New("edit out of range")
+	}
+
+	src := doc.src[:start] + text + doc.src[end:]
+	delta := len(text) - (end - start)
+	old := doc.lines
+
+	// The output up to a line start depends only on the source before it.
+	i := sort.Search(len(old), func(i int) bool { return old[i].src > start }) - 1
+	from := old[i]
+
+	// The conversion is the same from a line start after the edit that was
+	// a line start before, if nothing is pending at either.
+	j := 0
+	sync := func(cp checkpoint) bool {
+		if cp.src < start+len(text) {
+			return false
+		}
+		j = sort.Search(len(old), func(j int) bool { return old[j].src >= cp.src-delta })
+		return j < len(old) && old[j].src == cp.src-delta && old[j].state.resumes(cp.state)
+	}
+	out, lines, synced, err := doc.convert(src, from, sync)
+
+	p := Patch{Start: from.out, End: len(doc.greek), Greek: string(out)}
+	lines = append(old[:i:i], lines...)
+	if synced {
+		p.End = old[j].out
+		outDelta := len(out) - (p.End - p.Start)
+		lineDelta := strings.Count(text, "\n") - strings.Count(doc.src[start:end], "\n")
+		for _, cp := range old[j:] {
+			cp.src += delta
+			cp.out += outDelta
+			cp.state.d.pos.Offset += delta
+			cp.state.d.pos.Line += lineDelta
+			cp.state.d.out += outDelta
+			cp.state.d.lineSeg.m.InStart += delta
+			cp.state.d.lineSeg.m.OutStart += outDelta
+			lines = append(lines, cp)
+		}
+	}
+
+	greek := make([]byte, 0, len(doc.greek)+len(out)-(p.End-p.Start))
+	greek = append(greek, doc.greek[:p.Start]...)
+	greek = append(greek, out...)
+	if synced {
+		greek = append(greek, doc.greek[p.End:]...)
+	}
+
+	doc.src, doc.greek, doc.lines = src, greek, lines
+	return p, err
+}
+
+// convert converts src from the checkpoint cp to its end, or up to the
+// first line start at which sync returns true. It returns the output, the
+// checkpoints of the lines converted, cp first, and whether it stopped at
+// sync.
+func (doc *Document) convert(src string, cp checkpoint, sync func(cp checkpoint) bool) (out []byte, lines []checkpoint, synced bool, err error) {
+	d := Decoder{Options: doc.opts}
+	d.Restore(cp.state)
+	lines = []checkpoint{cp}
+
+	for i := cp.src; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		i += size
+		if out, err = d.pushSize(out, r, size); err != nil {
+			return out, lines, false, err
+		}
+		if r != '\n' {
+			continue
+		}
+
+		next := checkpoint{i, cp.out + len(out), d.State()}
+		if sync != nil && sync(next) {
+			return out, lines, true, nil
+		}
+		lines = append(lines, next)
+	}
+
+	out, err = d.end(out)
+	return out, lines, false, err
+}
+
+// resumes reports whether the conversion from t is the same as from s,
+// given the same input: neither has anything pending.
+func (s State) resumes(t State) bool {
+	clean := func(d *Decoder) bool {
+		return len(d.held) == 0 && d.sym.Empty() && !d.inWord && len(d.symSrc) == 0 && len(d.src) == 0
+	}
+	return clean(&s.d) && clean(&t.d) && s.d.literal == t.d.literal
+}
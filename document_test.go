@@ -0,0 +1,56 @@
+package beta
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	const src = "mh=nin a)/eide\nqea/ phlhi+a/dew\na)xilh=os\nou)lome/nhn\n"
+
+	doc, err := NewDocument(src, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		old, text string
+		local     bool // The old conversion is taken up again before its end
+	}{
+		{"qea/ ", "qea/, ", true},
+		{"a)xilh=o", "a)xilh=", true}, // Sigma at the end of a line
+		{"mh=nin", "{Lmh=nin", false}, // A literal region to the end
+		{"{L", "", false},             // and away again
+		{"qea/", "\nh)/\nqea/", true}, // New lines
+		{"ou)", "k/ ou)", true},       // Invalid
+		{"k/ ", "", false},            // Valid again, to the end
+		{"\n", "", true},              // Two lines joined
+	}
+
+	for _, tt := range tests {
+		before := doc.Greek()
+		start := strings.Index(doc.Source(), tt.old)
+		p, err := doc.Reconvert(start, start+len(tt.old), tt.text)
+
+		want, werr := ToGreek(doc.Source())
+		if (err == nil) != (werr == nil) {
+			t.Errorf("%q: expected error %v, got %v", tt.text, werr, err)
+		}
+		if werr != nil {
+			continue
+		}
+		if doc.Greek() != want {
+			t.Errorf("%q: expected %q, got %q", tt.text, want, doc.Greek())
+		}
+		if patched := before[:p.Start] + p.Greek + before[p.End:]; patched != want {
+			t.Errorf("%q: patch %v gives %q, expected %q", tt.text, p, patched, want)
+		}
+		if local := p.End < len(before); local != tt.local {
+			t.Errorf("%q: expected local patch %v, got %v", tt.text, tt.local, p)
+		}
+	}
+
+	if _, err := doc.Reconvert(3, 2, ""); err == nil {
+		t.Error("expected error for edit out of range")
+	}
+}
@@ -0,0 +1,317 @@
+package beta
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// An Encoder converts UTF-8 Greek, precombined or with combining
+// diacritics, to Betacode, like FromGreek, and writes it to an underlying
+// writer. Characters that are neither Greek letters nor diacritics are
+// copied unchanged. Like FromGreek, it accepts Greek in any normalization.
+//
+// Once an error has occurred, be it Greek without Betacode or a failed
+// write, all further Writes and Flushes return it.
+type Encoder struct {
+	// The Betacode written; by default TypeGreek, as by FromGreek.
+	Dialect Dialect
+
+	// The SigmaPolicy with which the Betacode is to be converted back,
+	// which decides how sigmas are written. With SigmaLunate, lunate
+	// sigmas are written like other sigmas.
+	Sigma SigmaPolicy
+
+	// The DigitPolicy with which the Betacode is to be converted back. The
+	// Standard dialects write final sigma as s2, lunate sigma as s3 and
+	// medial sigma as s1 at the end of a word or, with SigmaExplicit,
+	// everywhere, which a Decoder reads only with DigitsCodes; with Exact
+	// and another DigitPolicy, they write every sigma as s instead.
+	Digits DigitPolicy
+
+	// Greek that wouldn't be converted back unchanged by a Decoder with
+	// the Dialect, Sigma and Digits of the Encoder, like a medial sigma at
+	// the end of a word in TypeGreek, is an error.
+	Exact bool
+
+	// Which vowel of a diphthong after a capital carries the breathing
+	// and accent; by default, the one that has them in the Greek.
+	Diphthongs DiphthongMarks
+
+	w       *bufio.Writer
+	pending []byte // Input not encoded yet, from the last cluster on
+	err     error  // First error
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Write converts the Greek in p to Betacode. The last character of p is
+// held back until the next Write or Flush shows that no combining marks
+// follow and whether it ends a word. The Encoder must be Flushed for the
+// Write to take effect.
+func (e *Encoder) Write(p []byte) (n int, err error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	e.pending = append(e.pending, p...)
+	if err := e.encode(false); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteString is like Write for a string.
+func (e *Encoder) WriteString(s string) (n int, err error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	e.pending = append(e.pending, s...)
+	if err := e.encode(false); err != nil {
+		return 0, err
+	}
+	return len(s), nil
+}
+
+// Flush ends the input, writes out the character held back, if any, and
+// flushes the underlying buffer.
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+
+	if err := e.encode(true); err != nil {
+		return err
+	}
+	e.err = e.w.Flush()
+	return e.err
+}
+
+// encode writes the Betacode of the complete clusters pending and, if
+// atEnd, of all of them.
+func (e *Encoder) encode(atEnd bool) error {
+	// A prosgegrammeni split across Writes is only replaced when complete.
+	s := bytes.ReplaceAll(e.pending, []byte(prosgegrammeni), []byte(ypogegrammeni))
+	for len(s) > 0 {
+		n := clusterEnd(len(s), func(i int) (rune, int) {
+			return utf8.DecodeRune(s[i:])
+		})
+		if !atEnd && (n == len(s) || !utf8.FullRune(s[n:])) {
+			break
+		}
+
+		clusters := []string{string(s[:n])}
+		if e.Diphthongs != DiphthongsKeep && n < len(s) {
+			// The marks may move to or from the next cluster.
+			m := n + clusterEnd(len(s)-n, func(i int) (rune, int) {
+				return utf8.DecodeRune(s[n+i:])
+			})
+			if !atEnd && (m == len(s) || !utf8.FullRune(s[m:])) {
+				break
+			}
+			if a, b, ok := moveMarks(clusters[0], string(s[n:m]), e.Diphthongs); ok {
+				clusters = []string{a, b}
+				n = m
+			}
+		}
+
+		enc := encoding{e.Dialect, e.Sigma, e.Digits, e.Exact, e.Digits == DigitsCodes || !e.Exact}
+		for i, c := range clusters {
+			var next rune
+			if i+1 < len(clusters) {
+				next, _ = utf8.DecodeRuneInString(clusters[i+1])
+			} else if n < len(s) {
+				next, _ = utf8.DecodeRune(s[n:])
+			}
+			beta, err := enc.encodeCluster(c, next)
+			if err == nil {
+				_, err = e.w.WriteString(beta)
+			}
+			if err != nil {
+				e.err = err
+				return err
+			}
+		}
+		s = s[n:]
+	}
+
+	e.pending = append(e.pending[:0], s...)
+	return nil
+}
+
+// A DiphthongMarks decides where the breathing and accent of a diphthong
+// that begins with a capital go, as in Αἱ and Ἁι.
+type DiphthongMarks int
+
+const (
+	// The marks stay on the vowel that has them.
+	DiphthongsKeep DiphthongMarks = iota
+
+	// The marks go to the second vowel, as in Αἱ: Ai( or *ai(.
+	DiphthongsSecond
+
+	// The marks go to the capital, as in Ἁι: A(i or *(ai.
+	DiphthongsFirst
+)
+
+// moveMarks moves the breathing and accent of the Greek clusters a and b,
+// a capital vowel and the vowel after it, as by policy, and returns the new
+// clusters. It returns false if they don't form a diphthong or there is
+// nothing to move.
+func moveMarks(a, b string, policy DiphthongMarks) (string, string, bool) {
+	first, ok, err := clusterSym(norm.NFD.String(a))
+	if !ok || err != nil || !unicode.IsUpper(first.Base) || first.Iota {
+		return a, b, false
+	}
+	second, ok, err := clusterSym(norm.NFD.String(b))
+	if !ok || err != nil || !unicode.IsLower(second.Base) || second.Trema {
+		return a, b, false
+	}
+	if !isDiphthong(first.Base, second.Base) {
+		return a, b, false
+	}
+
+	from, to := &first, &second
+	if policy == DiphthongsFirst {
+		from, to = to, from
+	}
+	if from.Accent == 0 && from.Spiritus == 0 || to.Accent != 0 || to.Spiritus != 0 {
+		return a, b, false
+	}
+	to.Accent, to.Spiritus = from.Accent, from.Spiritus
+	from.Accent, from.Spiritus = 0, 0
+	return first.CombiningString(), second.CombiningString(), true
+}
+
+// An encoding is the Betacode that Greek is converted to.
+type encoding struct {
+	dialect Dialect
+	sigma   SigmaPolicy
+	digits  DigitPolicy
+	exact   bool // Greek that doesn't convert back unchanged is an error
+	codes   bool // The Standard dialects write sigmas as s1, s2 and s3
+}
+
+// encodeCluster returns the Betacode of a grapheme cluster. The cluster is
+// followed by the rune next, or 0 at the end of input. A cluster that is
+// not a Greek letter is returned unchanged.
+func (enc encoding) encodeCluster(cluster string, next rune) (string, error) {
+	// A sigma at the end of a word becomes final by itself.
+	end := !strings.ContainsRune(validCodes, next) && finalSigma(next)
+
+	var sym Sym
+	lunate := false
+	switch r, _ := utf8.DecodeRuneInString(cluster); r {
+	case '\u03F2':
+		sym.Base, lunate = 's', true
+	case '\u03F9':
+		sym.Base, lunate = 'S', true
+	}
+	if !lunate || len(cluster) > len("\u03F2") {
+		var ok bool
+		var err error
+		sym, ok, err = clusterSym(norm.NFD.String(cluster))
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return cluster, nil
+		}
+	}
+
+	var sb strings.Builder
+	base := sym.Base
+	if enc.dialect.asterisks() && unicode.IsUpper(sym.Base) {
+		sb.WriteByte('*')
+		if sym.Spiritus != 0 {
+			sb.WriteRune(sym.Spiritus)
+		}
+		if sym.Accent != 0 {
+			sb.WriteRune(sym.Accent)
+		}
+		sym.Base = unicode.ToLower(sym.Base)
+		sym.Spiritus, sym.Accent = 0, 0
+	}
+
+	if base == 's' || base == 'S' || base == 'j' {
+		code, exact := enc.sigmaCode(base, lunate, end)
+		if !exact && enc.exact {
+			return "", fmt.Errorf("%q does not convert back unchanged from %s Betacode", cluster, enc.dialect)
+		}
+		sb.WriteString(code)
+	} else {
+		sb.WriteString(sym.String())
+	}
+
+	code := sb.String()
+	dl := enc.dialect.table()
+	if dl.keys != nil {
+		code = strings.Map(func(r rune) rune { return remap(r, dl.keys) }, code)
+	}
+	if dl.Capitals {
+		code = strings.ToUpper(code)
+	}
+	return code, nil
+}
+
+// sigmaCode returns the Betacode of a sigma, s if medial, S if capital, j if
+// final; it is lunate if lunate, and at the end of a word if end. The code
+// is exact if it is converted back to the same sigma with the SigmaPolicy
+// and DigitPolicy.
+func (enc encoding) sigmaCode(base rune, lunate, end bool) (code string, exact bool) {
+	if enc.dialect.asterisks() && enc.codes {
+		codes := enc.digits == DigitsCodes
+		switch {
+		case lunate:
+			return "s3", codes
+		case base == 'S':
+			return "s", true
+		case base == 'j':
+			return "s2", codes
+		case end || enc.sigma == SigmaExplicit:
+			return "s1", codes
+		}
+		return "s", true
+	}
+
+	switch {
+	case enc.sigma == SigmaLunate && base == 'S':
+		code, exact = "S", lunate
+	case enc.sigma == SigmaLunate:
+		code, exact = "s", lunate
+	case lunate && base == 'S':
+		code, exact = "S", false
+	case lunate:
+		code, exact = "s", false
+	case base == 'S':
+		code, exact = "S", true
+	case base == 'j' && end && enc.sigma == SigmaAuto:
+		code, exact = "s", true
+	case base == 'j':
+		code, exact = "j", true
+	default:
+		code, exact = "s", !end || enc.sigma == SigmaExplicit
+	}
+
+	// Without the codes of the Standard dialects, the asterisk before the
+	// sigma makes the capital, and a final sigma within a word can't be
+	// written.
+	if enc.dialect.asterisks() {
+		switch code {
+		case "S":
+			code = "s"
+		case "j":
+			code, exact = "s", false
+		}
+	}
+	return code, exact
+}
@@ -0,0 +1,202 @@
+package beta
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	tests := []struct {
+		dialect     Dialect
+		greek, beta string
+	}{
+		{DialectTypeGreek, "Μῆνιν ἄειδε, θεά, Ἀχιλῆος", "Mh=nin a)/eide, qea/, A)xilh=os"},
+		{DialectTypeGreek, "λόγος λόγος-", "lo/gos lo/goj-"},
+		{DialectStandard, "Ἀχιλῆος ᾍδης", "*)axilh=os2 *(/a|dhs2"},
+		{DialectStandard, "λόγοσ σοφός", "lo/gos1 sofo/s2"},
+		{DialectTLG, "Ἀχιλῆος λόγοσ", "*)AXILH=OS2 LO/GOS1"},
+	}
+
+	for _, tt := range tests {
+		var sb strings.Builder
+		e := NewEncoder(&sb)
+		e.Dialect = tt.dialect
+
+		// Write in pieces that split characters and their marks.
+		b := []byte(tt.greek)
+		for i := 0; i < len(b); i += 3 {
+			end := i + 3
+			if end > len(b) {
+				end = len(b)
+			}
+			if _, err := e.Write(b[i:end]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		if sb.String() != tt.beta {
+			t.Errorf("expected %q, got %q", tt.beta, sb.String())
+		}
+	}
+}
+
+func TestEncoderError(t *testing.T) {
+	var sb strings.Builder
+	e := NewEncoder(&sb)
+
+	e.WriteString("θεά ϡ ")
+	if err := e.Flush(); err == nil {
+		t.Error("expected error for sampi")
+	}
+	if _, err := e.WriteString("α"); err == nil {
+		t.Error("expected the error again")
+	}
+}
+
+func TestEncoderNormalization(t *testing.T) {
+	var sb strings.Builder
+	e := NewEncoder(&sb)
+
+	// Tonos and oxia, and a prosgegrammeni split across Writes.
+	for _, s := range []string{"\u03AC \u1F71 \u1F67\xE1\xBE", "\xBE \u1FA7"} {
+		if _, err := e.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if sb.String() != "a/ a/ w(=| w(=|" {
+		t.Errorf("unexpected %q", sb.String())
+	}
+}
+
+func TestEncoderSigma(t *testing.T) {
+	tests := []struct {
+		dialect     Dialect
+		sigma       SigmaPolicy
+		greek, beta string
+		inexact     bool
+	}{
+		{DialectTypeGreek, SigmaAuto, "λόγος» λόγος-", "lo/gos» lo/goj-", false},
+		{DialectTypeGreek, SigmaAuto, "λόγοσ’", "lo/gos’", true},
+		{DialectTypeGreek, SigmaAuto, "\u03F2οφό\u03F2", "sofo/s", true},
+		{DialectTypeGreek, SigmaExplicit, "λόγοσ λόγος", "lo/gos lo/goj", false},
+		{DialectTypeGreek, SigmaLunate, "\u03F9ο\u03F2", "Sos", false},
+		{DialectTypeGreek, SigmaLunate, "σοφός", "sofo/s", true},
+		{DialectStandard, SigmaAuto, "Σοφός \u03F9 \u03F2", "*sofo/s2 *s3 s3", false},
+		{DialectStandard, SigmaExplicit, "σοφός", "s1ofo/s2", false},
+	}
+
+	for _, tt := range tests {
+		for _, exact := range []bool{false, true} {
+			var sb strings.Builder
+			e := NewEncoder(&sb)
+			e.Dialect, e.Sigma, e.Exact = tt.dialect, tt.sigma, exact
+			if tt.dialect == DialectStandard {
+				e.Digits = DigitsCodes
+			}
+
+			e.WriteString(tt.greek)
+			err := e.Flush()
+			switch {
+			case exact && tt.inexact:
+				if err == nil {
+					t.Errorf("%q: expected error with Exact", tt.greek)
+				}
+			case err != nil:
+				t.Errorf("%q: %v", tt.greek, err)
+			case sb.String() != tt.beta:
+				t.Errorf("%q: expected %q, got %q", tt.greek, tt.beta, sb.String())
+			}
+		}
+	}
+}
+
+func TestEncoderDiphthongs(t *testing.T) {
+	tests := []struct {
+		policy      DiphthongMarks
+		dialect     Dialect
+		greek, beta string
+	}{
+		{DiphthongsKeep, DialectStandard, "Αἵμων Ἅιδης", "*ai(/mwn *(/aidhs2"},
+		{DiphthongsSecond, DialectStandard, "Αἵμων Ἅιδης", "*ai(/mwn *ai(/dhs2"},
+		{DiphthongsSecond, DialectTypeGreek, "Ἅιδης", "Ai(/dhs"},
+		{DiphthongsFirst, DialectStandard, "Αἵμων", "*(/aimwn"},
+		{DiphthongsFirst, DialectTLG, "Αἵμων", "*(/AIMWN"},
+		{DiphthongsFirst, DialectTypeGreek, "Αἵ", "A(/i"},
+
+		// No diphthong: diaeresis, or a second vowel that isn't one
+		{DiphthongsFirst, DialectTypeGreek, "Αΐ", "Ai/+"},
+		{DiphthongsFirst, DialectTypeGreek, "Αἔ", "Ae)/"},
+	}
+
+	for _, tt := range tests {
+		var sb strings.Builder
+		e := NewEncoder(&sb)
+		e.Dialect = tt.dialect
+		e.Diphthongs = tt.policy
+
+		// Write one byte at a time, so that the second vowel comes later.
+		for _, b := range []byte(tt.greek) {
+			e.Write([]byte{b})
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if sb.String() != tt.beta {
+			t.Errorf("%+q: expected %q, got %q", tt.greek, tt.beta, sb.String())
+		}
+	}
+}
+
+func TestEncoderDigits(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		greek   string
+		beta    string
+	}{
+		{DialectStandard, "Σοφὸς Ἀχιλῆος ᾍδης", "*sofo\\s *)axilh=os *(/a|dhs"},
+		{DialectTLG, "Σοφὸς Ἀχιλῆος", "*SOFO\\S *)AXILH=OS"},
+		{DialectStandard, "λόγοσ\u03F2", ""},
+		{DialectStandard, "ςα", ""},
+	}
+
+	for _, tt := range tests {
+		var sb strings.Builder
+		e := NewEncoder(&sb)
+		e.Dialect, e.Exact = tt.dialect, true
+		e.WriteString(tt.greek)
+		err := e.Flush()
+		if tt.beta == "" {
+			if err == nil {
+				t.Errorf("%q: expected error without DigitsCodes, got %q", tt.greek, sb.String())
+			}
+			continue
+		}
+		if err != nil || sb.String() != tt.beta {
+			t.Errorf("%q: expected %q, got %q, %v", tt.greek, tt.beta, sb.String(), err)
+			continue
+		}
+
+		// The default Decoder reads the Betacode back unchanged.
+		out, err := ConvertAppend(nil, []byte(sb.String()), Options{Dialect: tt.dialect})
+		if err != nil || string(out) != tt.greek {
+			t.Errorf("%q: converted back to %q, %v", tt.greek, out, err)
+		}
+	}
+
+	// Without Exact, the codes are written, to be read with DigitsCodes.
+	beta, err := FromGreekDialect("λόγος", DialectStandard)
+	if err != nil || beta != "lo/gos2" {
+		t.Fatalf("expected lo/gos2, got %q, %v", beta, err)
+	}
+	out, err := ConvertAppend(nil, []byte(beta), Options{Dialect: DialectStandard, Digits: DigitsCodes})
+	if err != nil || string(out) != "λόγος" {
+		t.Errorf("expected λόγος, got %q, %v", out, err)
+	}
+}
@@ -0,0 +1,80 @@
+package beta
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// A Fold selects the differences that EqualGreekFold ignores.
+type Fold uint
+
+const (
+	FoldNorm  Fold = 1 << iota // Normalisation: precombined (NFC) and combining (NFD) forms are equal
+	FoldTonos                  // Oxia (e.g. U+1F71) and tonos (U+03AC) are equal
+	FoldSigma                  // Final (ς) and lunate (ϲ, Ϲ) sigma equal plain sigma
+)
+
+// Oxia code points and their tonos equivalents. Normalisation maps them too,
+// but FoldTonos also works without FoldNorm.
+var oxia = map[rune]rune{
+	'\u1F71': '\u03AC',
+	'\u1F73': '\u03AD',
+	'\u1F75': '\u03AE',
+	'\u1F77': '\u03AF',
+	'\u1F79': '\u03CC',
+	'\u1F7B': '\u03CD',
+	'\u1F7D': '\u03CE',
+	'\u1FBB': '\u0386',
+	'\u1FC9': '\u0388',
+	'\u1FCB': '\u0389',
+	'\u1FDB': '\u038A',
+	'\u1FF9': '\u038C',
+	'\u1FEB': '\u038E',
+	'\u1FFB': '\u038F',
+	'\u1FD3': '\u0390',
+	'\u1FE3': '\u03B0',
+	'\u1FEE': '\u0385',
+	'\u1FFD': '\u00B4',
+	'\u0341': '\u0301',
+}
+
+// EqualGreek reports whether a and b are the same Greek text, regardless of
+// normalisation form and of oxia versus tonos. Texts that look the same
+// often differ in these respects only.
+func EqualGreek(a, b string) bool {
+	return EqualGreekFold(a, b, FoldNorm|FoldTonos)
+}
+
+// EqualGreekFold reports whether a and b are equal after ignoring the
+// differences selected by f.
+func EqualGreekFold(a, b string, f Fold) bool {
+	return fold(a, f) == fold(b, f)
+}
+
+// fold returns s with the differences selected by f removed.
+func fold(s string, f Fold) string {
+	if f&FoldNorm != 0 {
+		s = norm.NFD.String(s)
+	}
+	if f&(FoldTonos|FoldSigma) == 0 {
+		return s
+	}
+
+	return strings.Map(func(r rune) rune {
+		if f&FoldTonos != 0 {
+			if t, ok := oxia[r]; ok {
+				return t
+			}
+		}
+		if f&FoldSigma != 0 {
+			switch r {
+			case 'ς', 'ϲ':
+				return 'σ'
+			case 'Ϲ':
+				return 'Σ'
+			}
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,29 @@
+package beta
+
+import "testing"
+
+func TestEqualGreek(t *testing.T) {
+	tests := []struct {
+		a, b string
+		f    Fold
+		eq   bool
+	}{
+		{"ἄειδε", "α\u0313\u0301ειδε", FoldNorm, true},
+		{"ἄειδε", "α\u0313\u0301ειδε", 0, false},
+		{"θε\u03AC", "θε\u1F71", FoldTonos, true},
+		{"θε\u03AC", "θε\u1F71", 0, false},
+		{"λόγος", "λόγοϲ", FoldSigma, true},
+		{"λόγος", "λόγοϲ", FoldNorm | FoldTonos, false},
+		{"λόγος", "λόγον", FoldNorm | FoldTonos | FoldSigma, false},
+	}
+
+	for _, tt := range tests {
+		if eq := EqualGreekFold(tt.a, tt.b, tt.f); eq != tt.eq {
+			t.Errorf("EqualGreekFold(%q, %q, %d): expected %v", tt.a, tt.b, tt.f, tt.eq)
+		}
+	}
+
+	if !EqualGreek("Ἀχιλῆος", "Α\u0313χιλη\u0342ος") {
+		t.Error("expected precombined and combining Ἀχιλῆος to be equal")
+	}
+}
@@ -0,0 +1,90 @@
+package beta
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// How Escape writes a grapheme cluster.
+const (
+	escCode    = iota // As Betacode
+	escRaw            // Unchanged, outside of a literal region
+	escLiteral        // Unchanged, inside a literal region
+)
+
+// Escape returns Betacode that ToGreek (or a Writer with default Options)
+// converts back to exactly greek. Greek letters become Betacode. Other text
+// is copied; if it would be taken for Betacode, like Latin letters or
+// slashes, or if it is Greek in a form the conversion doesn't produce, like
+// a medial sigma at the end of a word or text in NFD, it is put into a
+// literal region.
+func Escape(greek string) string {
+	var clusters []string
+	for s := greek; len(s) > 0; {
+		n := clusterLen(s)
+		clusters = append(clusters, s[:n])
+		s = s[n:]
+	}
+
+	// Each cluster's form depends on the next one, so go backwards.
+	modes := make([]int, len(clusters))
+	codes := make([]string, len(clusters))
+	for i := len(clusters) - 1; i >= 0; i-- {
+		c := clusters[i]
+		next, nextMode := "", escRaw
+		if i+1 < len(clusters) {
+			next, nextMode = codes[i+1], modes[i+1]
+			if nextMode != escCode {
+				next = clusters[i+1]
+			}
+		}
+		nextRune, _ := utf8.DecodeRuneInString(next)
+
+		sym, ok, err := clusterSym(norm.NFD.String(c))
+		switch {
+		case ok && err == nil && sym.PrecombinedString() == c:
+			modes[i] = escCode
+			codes[i] = sym.String()
+
+			// Sigma must be followed by the code of a letter or a rune that
+			// keeps it medial; final sigma can always be written as j.
+			switch {
+			case sym.Base == 's' && !(nextMode == escCode || nextMode == escRaw && next != "" && !finalSigma(nextRune)):
+				modes[i] = escLiteral
+			case sym.Base == 'j' && nextMode == escRaw && finalSigma(nextRune):
+				codes[i] = "s"
+			}
+
+		case ok || err != nil || strings.ContainsAny(c, validCodes):
+			modes[i] = escLiteral
+
+		case strings.HasSuffix(c, DefaultLiteralOpen[:1]) && nextMode == escCode && strings.HasPrefix(next, DefaultLiteralOpen[1:]):
+			// Together with the next letter, this would open a literal region.
+			modes[i] = escLiteral
+
+		default:
+			modes[i] = escRaw
+		}
+	}
+
+	var sb strings.Builder
+	for i, c := range clusters {
+		if modes[i] == escLiteral && (i == 0 || modes[i-1] != escLiteral) {
+			sb.WriteString(DefaultLiteralOpen)
+		}
+
+		if modes[i] == escCode {
+			sb.WriteString(codes[i])
+		} else {
+			sb.WriteString(c)
+		}
+
+		if modes[i] == escLiteral && (i+1 == len(clusters) || modes[i+1] != escLiteral) {
+			sb.WriteString(DefaultLiteralClose)
+		}
+	}
+
+	return sb.String()
+}
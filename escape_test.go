@@ -0,0 +1,32 @@
+package beta
+
+import "testing"
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		greek, beta string
+	}{
+		{"μῆνιν ἄειδε", "mh=nin a)/eide"},
+		{"λόγος (logos)", "lo/gos {L(logos)L}"},
+		{"λόγος-", "lo/goj-"},
+		{"λόγοσ.", "lo/go{LσL}."},
+		{"a/b", "{La/bL}"},
+		{"{Λόγος}", "{L{L}Lo/gos}"},
+		{"ά", "{LάL}"},
+		{"x L} y", "{LxL} {LLL}} {LyL}"},
+	}
+
+	for _, tt := range tests {
+		s := Escape(tt.greek)
+		if s != tt.beta {
+			t.Errorf("Escape(%q): expected %q, got %q", tt.greek, tt.beta, s)
+		}
+
+		g, err := ToGreek(s)
+		if err != nil {
+			t.Errorf("ToGreek(%q): %v", s, err)
+		} else if g != tt.greek {
+			t.Errorf("ToGreek(Escape(%q)): got %q", tt.greek, g)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package beta
+
+import (
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// A Fingerprint is an io.Writer that hashes the Greek text written to it,
+// like the output of a Writer, so that texts that differ only in
+// normalization, oxia versus tonos or the layout of blanks and line breaks
+// have the same fingerprint. Two Betacode files typed differently, like
+// A)/ndra and *)/andra or with precombined and combining output, can so be
+// recognised as the same Greek without keeping either conversion.
+type Fingerprint struct {
+	h   hash.Hash
+	nfc io.WriteCloser
+	sum []byte
+}
+
+// NewFingerprint returns a Fingerprint of SHA-256.
+func NewFingerprint() *Fingerprint {
+	f := &Fingerprint{h: sha256.New()}
+	f.nfc = norm.NFC.Writer(&blankFolder{w: f.h})
+	return f
+}
+
+// Write adds Greek text to the fingerprint. It fails after Sum.
+func (f *Fingerprint) Write(p []byte) (int, error) {
+	if f.sum != nil {
+		return 0, errFingerprintDone
+	}
+	return f.nfc.Write(p)
+}
+
+// Sum ends the text and returns the fingerprint.
+func (f *Fingerprint) Sum() []byte {
+	if f.sum == nil {
+		f.nfc.Close()
+		f.sum = f.h.Sum(nil)
+	}
+	return f.sum
+}
+
+// Error of a Write after Sum
+var errFingerprintDone = errors.New("write to a Fingerprint after Sum")
+
+// A blankFolder writes text to w with runs of blanks and line breaks as
+// one space, and none at the start and end.
+type blankFolder struct {
+	w       io.Writer
+	blank   bool // Blanks are pending
+	started bool // Text other than blanks has been written
+}
+
+func (bf *blankFolder) Write(p []byte) (int, error) {
+	for i := 0; i < len(p); {
+		j := i
+		for j < len(p) && !blank(p[j]) {
+			j++
+		}
+		if j > i {
+			if bf.blank && bf.started {
+				if _, err := bf.w.Write([]byte{' '}); err != nil {
+					return 0, err
+				}
+			}
+			if _, err := bf.w.Write(p[i:j]); err != nil {
+				return 0, err
+			}
+			bf.blank, bf.started = false, true
+		}
+		if j < len(p) {
+			bf.blank = true
+			j++
+		}
+		i = j
+	}
+	return len(p), nil
+}
+
+func blank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
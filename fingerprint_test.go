@@ -0,0 +1,53 @@
+package beta
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := func(src string, opts Options) []byte {
+		f := NewFingerprint()
+		w := NewWriter(f)
+		w.Options = opts
+		// Split, so that normalization spans writes.
+		for _, part := range strings.SplitAfter(src, "/") {
+			io.WriteString(w, part)
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		return f.Sum()
+	}
+
+	ref := fingerprint("A)/ndra moi e)/nnepe", Options{})
+	same := []struct {
+		src  string
+		opts Options
+	}{
+		{"*)/andra moi e)/nnepe", Options{}},
+		{"A)/ndra moi e)/nnepe", Options{Combining: true}},
+		{"  A)/ndra\n\tmoi   e)/nnepe\n", Options{}},
+	}
+	for _, tt := range same {
+		if f := fingerprint(tt.src, tt.opts); !bytes.Equal(f, ref) {
+			t.Errorf("%q: fingerprint differs", tt.src)
+		}
+	}
+	if f := fingerprint("A)/ndra moi e)/nnepen", Options{}); bytes.Equal(f, ref) {
+		t.Error("expected a different fingerprint")
+	}
+
+	// Oxia and tonos
+	a, b := NewFingerprint(), NewFingerprint()
+	a.Write([]byte("\u1F71"))
+	b.Write([]byte("\u03AC"))
+	if !bytes.Equal(a.Sum(), b.Sum()) {
+		t.Error("oxia and tonos differ")
+	}
+	if _, err := a.Write([]byte("x")); err == nil {
+		t.Error("expected error for a write after Sum")
+	}
+}
@@ -0,0 +1,243 @@
+package beta
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FormatOptions are the conventions of Format.
+type FormatOptions struct {
+	// How the source is read: its Dialect, whether letters of either case
+	// are the same (IgnoreCase), the delimiters of literal regions and
+	// whether Markers and the codes of Digits are recognised. The other
+	// Options are not used.
+	Options Options
+
+	// How capitals are written: as capital letters with their
+	// diacritics after them (A)/), by default, with an asterisk and the
+	// diacritics before the letter (*)/a), or all in capitals (*)/A). The
+	// sigmas are written with the codes s1, s2 and s3 of the Standard
+	// dialects only if Options.Digits is DigitsCodes, so that the output
+	// is read like the source.
+	Dialect Dialect
+
+	// If positive, lines longer than Width runes are wrapped at spaces,
+	// and the continuation lines get the indentation of the first.
+	Width int
+}
+
+// A formatLine is a line of formatted Betacode: its indentation and its
+// fields, the runs of text between blanks.
+type formatLine struct {
+	indent string
+	fields []string
+}
+
+// Format rewrites the Betacode src in a consistent form, like gofmt does
+// for Go: the symbols with their diacritics in the order of Sym.String and
+// capitals and sigmas in the convention of the dialect of opts, blanks
+// within lines collapsed to one space, without trailing blanks, without
+// more than one blank line in a row and with a final newline. Indentation,
+// literal regions, markers and the signs of DigitsCodes are kept as they
+// are. On invalid Betacode, Format returns a *SyntaxError.
+func Format(src string, opts FormatOptions) (string, error) {
+	rd := Decoder{Options: opts.Options}
+	enc := encoding{dialect: opts.Dialect, digits: opts.Options.Digits, codes: opts.Options.Digits == DigitsCodes}
+	open, close := rd.literalDelims()
+	codes := rd.Digits == DigitsCodes
+
+	var lines []formatLine
+	var line formatLine
+	var field strings.Builder
+	endField := func() {
+		if field.Len() > 0 {
+			line.fields = append(line.fields, field.String())
+			field.Reset()
+		}
+	}
+	// copySrc adds src[i:j] to the field as it is.
+	pos := Position{Line: 1, Col: 1}
+	copySrc := func(i, j int) {
+		field.WriteString(src[i:j])
+		for _, r := range src[i:j] {
+			pos.advance(r)
+		}
+	}
+	isCode := func(r rune) bool {
+		return strings.ContainsRune(validCodes, rd.code(r))
+	}
+
+	literal := false
+	for i := 0; i < len(src); {
+		delim := open
+		if literal {
+			delim = close
+		}
+		if strings.HasPrefix(src[i:], delim) {
+			copySrc(i, i+len(delim))
+			i += len(delim)
+			literal = !literal
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(src[i:])
+		if !literal && codes {
+			// The codes are ASCII, so bytes will do for runes.
+			if mc, _ := lexCode(len(src)-i, func(k int) rune { return rune(src[i+k]) }, true); mc.kind == codeSign {
+				copySrc(i, i+mc.n)
+				i += mc.n
+				continue
+			}
+		}
+
+		switch {
+		case literal:
+			field.WriteRune(r)
+
+		case isCode(r):
+			// The word goes on with Betacode and, with DigitsCodes, the
+			// digits of numbered sigmas, up to a delimiter or a marker.
+			j := i
+			for j < len(src) && !strings.HasPrefix(src[j:], open) {
+				c, n := utf8.DecodeRuneInString(src[j:])
+				switch {
+				case codes && rd.code(c) == 's' && j+1 < len(src) && '1' <= src[j+1] && src[j+1] <= '3' && rd.Markers == MarkersText:
+					n = 2
+				case !isCode(c):
+					n = 0
+				}
+				if n == 0 {
+					break
+				}
+				j += n
+			}
+			next, _ := utf8.DecodeRuneInString(src[j:])
+			if rd.Markers != MarkersText && ('0' <= next && next <= '9' || next == '[') {
+				// A marker ends the word.
+				next = 0
+			}
+			w, err := formatWord(src[i:j], next, &rd, enc)
+			if err != nil {
+				return "", &SyntaxError{Pos: pos, Msg: err.Error()}
+			}
+			field.WriteString(w)
+			for _, r := range src[i:j] {
+				pos.advance(r)
+			}
+			i = j
+			continue
+
+		case r == ' ' || r == '\t':
+			if len(line.fields) == 0 && field.Len() == 0 {
+				line.indent += string(r)
+			}
+			endField()
+
+		case r == '\r' && strings.HasPrefix(src[i+size:], "\n"):
+			// Lines end in LF only.
+
+		case r == '\n':
+			endField()
+			lines = append(lines, line)
+			line = formatLine{}
+
+		default:
+			field.WriteRune(r)
+		}
+		pos.advance(r)
+		i += size
+	}
+	endField()
+	if len(line.fields) > 0 {
+		lines = append(lines, line)
+	}
+
+	var sb strings.Builder
+	blank := false
+	for _, l := range lines {
+		if len(l.fields) == 0 {
+			blank = sb.Len() > 0
+			continue
+		}
+		if blank {
+			sb.WriteByte('\n')
+			blank = false
+		}
+
+		sb.WriteString(l.indent)
+		n := utf8.RuneCountInString(l.indent)
+		for k, f := range l.fields {
+			m := utf8.RuneCountInString(f)
+			switch {
+			case k == 0:
+			case opts.Width > 0 && n+1+m > opts.Width:
+				sb.WriteByte('\n')
+				sb.WriteString(l.indent)
+				n = utf8.RuneCountInString(l.indent)
+			default:
+				sb.WriteByte(' ')
+				n++
+			}
+			sb.WriteString(f)
+			n += m
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String(), nil
+}
+
+// formatWord returns the Betacode word w, followed by next, read by rd and
+// written by enc. With DigitsCodes, w may contain numbered sigmas.
+func formatWord(w string, next rune, rd *Decoder, enc encoding) (string, error) {
+	var codes []rune
+	var lunate []bool // Whether each letter is a lunate sigma
+	medial := false   // The word ends in s1
+	for _, r := range w {
+		r = rd.code(r)
+		switch {
+		case '1' <= r && r <= '3':
+			// The digit of a numbered sigma
+			medial = r == '1'
+			if r == '2' {
+				codes[len(codes)-1] = 'j'
+			}
+			lunate[len(lunate)-1] = r == '3'
+			continue
+		case unicode.IsLetter(r):
+			lunate = append(lunate, false)
+		}
+		medial = false
+		codes = append(codes, r)
+	}
+
+	syms, err := parseWord(string(codes), next)
+	if err != nil {
+		return "", err
+	}
+	if n := len(syms); n > 0 && medial && syms[n-1].Base == 'j' {
+		syms[n-1].Base = 's'
+	}
+
+	var sb strings.Builder
+	for i, sym := range syms {
+		after := next
+		if i+1 < len(syms) {
+			after, _ = utf8.DecodeRuneInString(syms[i+1].CombiningString())
+		}
+		cluster := sym.CombiningString()
+		if i < len(lunate) && lunate[i] {
+			cluster = "\u03F2"
+			if unicode.IsUpper(sym.Base) {
+				cluster = "\u03F9"
+			}
+		}
+		s, err := enc.encodeCluster(cluster, after)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(s)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,43 @@
+package beta
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		opts      FormatOptions
+		src, want string
+	}{
+		{FormatOptions{}, "\n\nmh=nin  a/)eide,\tqea/  \r\n\n\n\n  *)/axilh=os lo/gos\n\n", "mh=nin a)/eide, qea/\n\n  A)/xilh=os lo/gos\n"},
+		{FormatOptions{}, "{Lp.  5\nL}  w|)=", "{Lp.  5\nL} w)=|\n"},
+		{FormatOptions{Dialect: DialectStandard}, "A)/xilh=os lo/gos", "*)/axilh=os lo/gos\n"},
+		{FormatOptions{Options: Options{Digits: DigitsCodes}, Dialect: DialectStandard}, "A)/xilh=os lo/gos", "*)/axilh=os2 lo/gos2\n"},
+		{FormatOptions{Options: Options{Dialect: DialectTLG, Digits: DigitsCodes}, Dialect: DialectTLG}, "*)/AXILH=OS LO/GOS", "*)/AXILH=OS2 LO/GOS2\n"},
+		{FormatOptions{Options: Options{Dialect: DialectTLG}}, "*)/AXILH=OS LO/GOS", "A)/xilh=os lo/gos\n"},
+		{FormatOptions{}, "A)XILLEU/S  lo/gos", "A)XILLEU/S lo/gos\n"},
+		{FormatOptions{Options: Options{Digits: DigitsCodes}, Dialect: DialectStandard}, "lo/gos1  *s3ofo/s [1a]1", "lo/gos1 *s3ofo/s2 [1a]1\n"},
+		{FormatOptions{Options: Options{LiteralOpen: "<<", LiteralClose: ">>", Markers: MarkersKeep}}, "<<a  b>>  lo/gos12", "<<a  b>> lo/gos12\n"},
+		{FormatOptions{Options: Options{Dialect: DialectKeyboard}}, "uea/", "qea/\n"},
+		{FormatOptions{Width: 12}, "  mh=nin a)/eide, qea/ phlhi+a/dew\n", "  mh=nin\n  a)/eide,\n  qea/\n  phlhi+a/dew\n"},
+	}
+
+	for _, tt := range tests {
+		s, err := Format(tt.src, tt.opts)
+		if err != nil {
+			t.Errorf("%q: %v", tt.src, err)
+		} else if s != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.src, tt.want, s)
+		}
+
+		// Formatting is idempotent, read as written.
+		again := tt.opts
+		again.Options.Dialect = tt.opts.Dialect
+		if s2, err := Format(s, again); err != nil || s2 != s {
+			t.Errorf("%q: formatted again: %q, %v", s, s2, err)
+		}
+	}
+
+	_, err := Format("qea/\nk/", FormatOptions{})
+	if serr, ok := err.(*SyntaxError); !ok || serr.Pos.Line != 2 {
+		t.Errorf("expected SyntaxError on line 2, got %v", err)
+	}
+}
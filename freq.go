@@ -0,0 +1,66 @@
+package beta
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Frequencies is a Sink that counts the symbols of Betacode, for studies of
+// orthography and letter forms. Write a corpus to NewSinkWriter(f) to count
+// it in one pass; the symbols are counted as the Options make them, so with
+// Plain, for instance, only the letters are told apart.
+type Frequencies struct {
+	Syms    map[Sym]int    // By symbol, with its diacritics
+	Letters map[rune]int   // By lowercase base letter; final sigma is j
+	Marks   map[string]int // By the diacritics of a symbol as in Sym.String, like ")/" or ""
+	Symbols int
+	Words   int
+}
+
+// NewFrequencies returns empty Frequencies.
+func NewFrequencies() *Frequencies {
+	return &Frequencies{
+		Syms:    map[Sym]int{},
+		Letters: map[rune]int{},
+		Marks:   map[string]int{},
+	}
+}
+
+func (f *Frequencies) WriteSymbol(sym Sym) error {
+	f.Syms[sym]++
+	f.Letters[unicode.ToLower(sym.Base)]++
+	f.Marks[sym.String()[1:]]++
+	f.Symbols++
+	return nil
+}
+
+func (f *Frequencies) WriteBoundary() error {
+	f.Words++
+	return nil
+}
+
+func (f *Frequencies) WriteRaw(p []byte) error {
+	return nil
+}
+
+// A SymCount is a symbol with its count.
+type SymCount struct {
+	Sym Sym
+	N   int
+}
+
+// Ranked returns the symbols by descending count, and those of the same
+// count in the order of their Betacode.
+func (f *Frequencies) Ranked() []SymCount {
+	ranked := make([]SymCount, 0, len(f.Syms))
+	for sym, n := range f.Syms {
+		ranked = append(ranked, SymCount{sym, n})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].N != ranked[j].N {
+			return ranked[i].N > ranked[j].N
+		}
+		return ranked[i].Sym.String() < ranked[j].Sym.String()
+	})
+	return ranked
+}
@@ -0,0 +1,30 @@
+package beta
+
+import "testing"
+
+func TestFrequencies(t *testing.T) {
+	f := NewFrequencies()
+	w := NewSinkWriter(f)
+	w.WriteString("a)/nqrwpos a)/neu lo/gou, {Lxyz L}*)/a")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Symbols != 18 || f.Words != 4 {
+		t.Errorf("expected 18 symbols in 4 words, got %d in %d", f.Symbols, f.Words)
+	}
+	if n := f.Syms[Sym{Base: 'a', Accent: '/', Spiritus: ')'}]; n != 2 {
+		t.Errorf("expected a)/ twice, got %d", n)
+	}
+	if f.Letters['a'] != 3 || f.Letters['j'] != 1 || f.Letters['s'] != 0 {
+		t.Errorf("unexpected letters %v", f.Letters)
+	}
+	if f.Marks[")/"] != 3 || f.Marks["/"] != 1 || f.Marks[""] != 14 {
+		t.Errorf("unexpected marks %v", f.Marks)
+	}
+
+	ranked := f.Ranked()
+	if len(ranked) != len(f.Syms) || ranked[0] != (SymCount{Sym{Base: 'a', Accent: '/', Spiritus: ')'}, 2}) || ranked[1] != (SymCount{Sym{Base: 'n'}, 2}) {
+		t.Errorf("unexpected ranking %v", ranked)
+	}
+}
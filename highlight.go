@@ -0,0 +1,130 @@
+package beta
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// A Class is the role of a piece of Betacode source, for syntax
+// highlighting.
+type Class int
+
+const (
+	ClassText       Class = iota // Not Betacode: whitespace, punctuation, literal regions
+	ClassLetter                  // Base letter
+	ClassAccent                  // Accent, iota subscript or diaeresis
+	ClassBreathing               // Smooth or rough breathing
+	ClassStructural              // Asterisk, delimiter of a literal region, marker or code of DigitsCodes
+	ClassInvalid                 // Code the Decoder reports an error for
+)
+
+var classNames = []string{"text", "letter", "accent", "breathing", "structural", "invalid"}
+
+func (c Class) String() string {
+	if c < 0 || int(c) >= len(classNames) {
+		return "Class(" + strconv.Itoa(int(c)) + ")"
+	}
+	return classNames[c]
+}
+
+// A Span is a range of Betacode source of one Class.
+type Span struct {
+	Class Class
+	Start int // Byte offset of the span in the source
+	End   int // Byte offset after the span
+}
+
+// Classify splits the Betacode src into Spans that cover it without gaps,
+// joining neighbouring source of the same Class. The source is read by a
+// Decoder with opts, so that literal regions, escapes, markers, Rules and
+// what is invalid agree with the conversion; the Handler, Map, Marker,
+// OnWord, OnLine and Warn of opts are not used. Input that a Rule drops is
+// text.
+func Classify(src string, opts Options) []Span {
+	bad := map[int]bool{}
+	opts.Handler = func(err error, pos Position, source string) Action {
+		bad[pos.Offset] = true
+		return Skip
+	}
+	opts.Map = nil
+	opts.Marker = nil
+	opts.OnWord = nil
+	opts.OnLine = nil
+	opts.Warn = nil
+	d := Decoder{Options: opts}
+	var read []Span
+	d.classify = func(c Class, in [2]int) {
+		read = append(read, Span{c, in[0], in[1]})
+	}
+	d.convert(nil, src)
+
+	var spans []Span
+	add := func(c Class, start, end int) {
+		if n := len(spans); n > 0 && spans[n-1].Class == c {
+			spans[n-1].End = end
+			return
+		}
+		spans = append(spans, Span{c, start, end})
+	}
+
+	// Errors are reported after their input is read, so the spans are
+	// only found invalid now.
+	at := 0
+	for _, sp := range read {
+		if sp.Start < at {
+			sp.Start = at
+		}
+		if sp.Start >= sp.End {
+			continue
+		}
+		if sp.Start > at {
+			add(ClassText, at, sp.Start)
+		}
+		if sp.Class != ClassText && bad[sp.Start] {
+			sp.Class = ClassInvalid
+		}
+		add(sp.Class, sp.Start, sp.End)
+		at = sp.End
+	}
+	if at < len(src) {
+		add(ClassText, at, len(src))
+	}
+
+	return spans
+}
+
+// codeClass returns the Class of the rune r outside of literal regions.
+func codeClass(r rune) Class {
+	switch {
+	case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		return ClassLetter
+	case r == '(' || r == ')':
+		return ClassBreathing
+	case r == '*':
+		return ClassStructural
+	case strings.ContainsRune(validCodes, r):
+		return ClassAccent
+	}
+	return ClassText
+}
+
+// HighlightHTML returns the Betacode src as HTML, with each Span but text
+// in a span element of the class "beta-" followed by the name of its Class,
+// like <span class="beta-accent">/</span>, for styling by CSS.
+func HighlightHTML(src string, opts Options) string {
+	var sb strings.Builder
+
+	for _, sp := range Classify(src, opts) {
+		text := html.EscapeString(src[sp.Start:sp.End])
+		if sp.Class == ClassText {
+			sb.WriteString(text)
+			continue
+		}
+		sb.WriteString(`<span class="beta-` + sp.Class.String() + `">`)
+		sb.WriteString(text)
+		sb.WriteString("</span>")
+	}
+
+	return sb.String()
+}
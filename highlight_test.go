@@ -0,0 +1,117 @@
+package beta
+
+import (
+	"reflect"
+	"testing"
+	"unicode"
+)
+
+func TestClassify(t *testing.T) {
+	const src = "*)/a k/ {Lx L}, "
+
+	ref := []Span{
+		{ClassStructural, 0, 1},
+		{ClassBreathing, 1, 2},
+		{ClassAccent, 2, 3},
+		{ClassLetter, 3, 4},
+		{ClassText, 4, 5},
+		{ClassLetter, 5, 6},
+		{ClassInvalid, 6, 7},
+		{ClassText, 7, 8},
+		{ClassStructural, 8, 10},
+		{ClassText, 10, 12},
+		{ClassStructural, 12, 14},
+		{ClassText, 14, 16},
+	}
+
+	spans := Classify(src, Options{})
+	if len(spans) != len(ref) {
+		t.Fatalf("expected %v, got %v", ref, spans)
+	}
+	for i := range ref {
+		if spans[i] != ref[i] {
+			t.Errorf("expected %v, got %v", ref[i], spans[i])
+		}
+	}
+
+	spans = Classify("os2 [1", Options{Digits: DigitsCodes})
+	if len(spans) != 4 || spans[1] != (Span{ClassStructural, 2, 3}) || spans[3] != (Span{ClassStructural, 4, 6}) {
+		t.Errorf("expected s2 and [1 to be codes, got %v", spans)
+	}
+
+	spans = Classify("e=", Options{Level: LevelStandard})
+	if len(spans) != 2 || spans[1].Class != ClassInvalid {
+		t.Errorf("expected the circumflex to be invalid, got %v", spans)
+	}
+}
+
+func TestClassifyOptions(t *testing.T) {
+	rules := []Rule{{From: '!', Mark: "\u0323"}, {From: 'v'}}
+	tests := []struct {
+		src  string
+		opts Options
+		ref  map[int]Class // Classes of some bytes
+	}{
+		{"a!&#98;v <<e/ *>> lo/gos2 [1 *", Options{LiteralOpen: "<<", LiteralClose: ">>", Escapes: true, Rules: rules, Digits: DigitsCodes},
+			map[int]Class{1: ClassAccent, 2: ClassLetter, 7: ClassText, 9: ClassStructural, 11: ClassText, 14: ClassText, 15: ClassStructural, 24: ClassStructural, 26: ClassStructural, 29: ClassInvalid}},
+		{"qea/12 lo/gos[3] {La/L}", Options{Markers: MarkersKeep},
+			map[int]Class{4: ClassStructural, 13: ClassStructural, 19: ClassText}},
+		{"e= *)a *", Options{Level: LevelStandard, Dialect: DialectTLG, Recovery: RecoverVerbatim, VerbatimOpen: "[", VerbatimClose: "]"},
+			map[int]Class{1: ClassInvalid, 4: ClassBreathing, 7: ClassInvalid}},
+	}
+
+	for _, tt := range tests {
+		class := make([]Class, len(tt.src))
+		invalid := map[int]bool{}
+		for _, sp := range Classify(tt.src, tt.opts) {
+			if sp.Class == ClassInvalid {
+				invalid[sp.Start] = true
+			}
+			for i := sp.Start; i < sp.End; i++ {
+				class[i] = sp.Class
+			}
+		}
+		for i, c := range tt.ref {
+			if class[i] != c {
+				t.Errorf("%q: expected byte %d to be %v, got %v", tt.src, i, c, class[i])
+			}
+		}
+
+		// Greek comes of letters and diacritics, and text doesn't.
+		var maps []Mapping
+		bad := map[int]bool{}
+		opts := tt.opts
+		opts.Map = func(m Mapping) { maps = append(maps, m) }
+		opts.Handler = func(err error, pos Position, source string) Action {
+			bad[pos.Offset] = true
+			return Skip
+		}
+		out, err := ConvertAppend(nil, []byte(tt.src), opts)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.src, err)
+		}
+		for _, m := range maps {
+			greek := false
+			for _, r := range string(out[m.OutStart:m.OutEnd]) {
+				greek = greek || unicode.Is(unicode.Greek, r)
+			}
+			for i := m.InStart; i < m.InEnd; i++ {
+				c := class[i]
+				if greek && c == ClassText || !greek && c != ClassText && c != ClassStructural {
+					t.Errorf("%q: byte %d is %v, but converted to %q", tt.src, i, class[i], out[m.OutStart:m.OutEnd])
+				}
+			}
+		}
+		if !reflect.DeepEqual(invalid, bad) {
+			t.Errorf("%q: expected invalid spans at %v, got %v", tt.src, bad, invalid)
+		}
+	}
+}
+
+func TestHighlightHTML(t *testing.T) {
+	const ref = `<span class="beta-letter">qea</span><span class="beta-accent">/</span> &lt;&amp;`
+
+	if s := HighlightHTML("qea/ <&", Options{}); s != ref {
+		t.Errorf("expected %q, got %q", ref, s)
+	}
+}
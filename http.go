@@ -0,0 +1,202 @@
+package beta
+
+import (
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Charset is the charset parameter that marks a Betacode HTTP response body,
+// as in "text/plain; charset=x-betacode".
+const Charset = "x-betacode"
+
+// Handler returns a handler that converts the bodies of h's responses to
+// precombined Greek if their Content-Type has the charset parameter Charset.
+// The charset is changed to utf-8. Other responses pass through untouched.
+// The ResponseWriter passed to h is an http.Flusher, http.Hijacker or
+// http.Pusher if rw is; its Flush sends the Greek converted so far. An error
+// converting the end of a response, which is too late to answer, is logged
+// to the ErrorLog of the http.Server, or else by the log package.
+func Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		bw := &betaResponse{ResponseWriter: rw}
+		h.ServeHTTP(bw.wrap(), r)
+		if bw.w == nil {
+			return
+		}
+		if err := bw.w.Flush(); err != nil {
+			logf(r, "beta: converting the response to %s: %v", r.URL, err)
+		}
+	})
+}
+
+// logf logs to the ErrorLog of the server of r, if any, and otherwise by
+// the log package.
+func logf(r *http.Request, format string, args ...interface{}) {
+	if srv, ok := r.Context().Value(http.ServerContextKey).(*http.Server); ok && srv.ErrorLog != nil {
+		srv.ErrorLog.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// FormHandler returns a handler that converts the values of the named form
+// fields from Betacode to precombined Greek before calling h, in URL
+// encoded and multipart forms alike. Requests with unparsable forms or
+// invalid Betacode are answered with 400 Bad Request.
+func FormHandler(h http.Handler, fields ...string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var err error
+		mediatype, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediatype == "multipart/form-data" {
+			err = r.ParseMultipartForm(formMemory)
+		} else {
+			err = r.ParseForm()
+		}
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		forms := []map[string][]string{r.Form, r.PostForm}
+		if r.MultipartForm != nil {
+			forms = append(forms, r.MultipartForm.Value)
+		}
+		for _, field := range fields {
+			for _, vals := range forms {
+				for i, v := range vals[field] {
+					g, err := ToGreek(v)
+					if err != nil {
+						http.Error(rw, field+": "+err.Error(), http.StatusBadRequest)
+						return
+					}
+					vals[field][i] = g
+				}
+			}
+		}
+
+		h.ServeHTTP(rw, r)
+	})
+}
+
+// Bytes of a multipart form kept in memory, as by http.Request.FormValue
+const formMemory = 32 << 20
+
+// betaResponse converts the body if the header says it is Betacode.
+type betaResponse struct {
+	http.ResponseWriter
+	w           *Writer // nil if the body is not converted
+	wroteHeader bool
+}
+
+func (bw *betaResponse) WriteHeader(status int) {
+	if bw.wroteHeader {
+		return
+	}
+	bw.wroteHeader = true
+
+	h := bw.Header()
+	mediatype, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err == nil && strings.EqualFold(params["charset"], Charset) {
+		params["charset"] = "utf-8"
+		h.Set("Content-Type", mime.FormatMediaType(mediatype, params))
+		h.Del("Content-Length")
+		bw.w = NewWriter(bw.ResponseWriter)
+	}
+
+	bw.ResponseWriter.WriteHeader(status)
+}
+
+func (bw *betaResponse) Write(p []byte) (int, error) {
+	if !bw.wroteHeader {
+		bw.WriteHeader(http.StatusOK)
+	}
+	if bw.w == nil {
+		return bw.ResponseWriter.Write(p)
+	}
+
+	if _, err := bw.w.Write(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ReadFrom copies r to the response, by the ResponseWriter's own ReadFrom
+// if the body is not converted.
+func (bw *betaResponse) ReadFrom(r io.Reader) (int64, error) {
+	if !bw.wroteHeader {
+		bw.WriteHeader(http.StatusOK)
+	}
+	if rf, ok := bw.ResponseWriter.(io.ReaderFrom); ok && bw.w == nil {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(struct{ io.Writer }{bw}, r)
+}
+
+// flush sends the Greek converted so far on to the client. A symbol that
+// may go on in the next Write is held back.
+func (bw *betaResponse) flush() {
+	if bw.w != nil && bw.w.err == nil {
+		bw.w.err = bw.w.flushBuf()
+	}
+	bw.ResponseWriter.(http.Flusher).Flush()
+}
+
+// A flusher is the Flush method of a ResponseWriter.
+type flusher func()
+
+func (f flusher) Flush() { f() }
+
+// wrap returns bw with the optional interfaces of its ResponseWriter.
+func (bw *betaResponse) wrap() http.ResponseWriter {
+	_, isF := bw.ResponseWriter.(http.Flusher)
+	h, isH := bw.ResponseWriter.(http.Hijacker)
+	p, isP := bw.ResponseWriter.(http.Pusher)
+	f := flusher(bw.flush)
+
+	switch {
+	case isF && isH && isP:
+		return struct {
+			*betaResponse
+			flusher
+			http.Hijacker
+			http.Pusher
+		}{bw, f, h, p}
+	case isF && isH:
+		return struct {
+			*betaResponse
+			flusher
+			http.Hijacker
+		}{bw, f, h}
+	case isF && isP:
+		return struct {
+			*betaResponse
+			flusher
+			http.Pusher
+		}{bw, f, p}
+	case isH && isP:
+		return struct {
+			*betaResponse
+			http.Hijacker
+			http.Pusher
+		}{bw, h, p}
+	case isF:
+		return struct {
+			*betaResponse
+			flusher
+		}{bw, f}
+	case isH:
+		return struct {
+			*betaResponse
+			http.Hijacker
+		}{bw, h}
+	case isP:
+		return struct {
+			*betaResponse
+			http.Pusher
+		}{bw, p}
+	}
+	return bw
+}
@@ -0,0 +1,145 @@
+package beta
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	h := Handler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/beta" {
+			rw.Header().Set("Content-Type", "text/plain; charset="+Charset)
+		} else {
+			rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		fmt.Fprint(rw, "mh=nin a)/eide")
+	}))
+
+	tests := []struct {
+		path, body, ctype string
+	}{
+		{"/beta", "μῆνιν ἄειδε", "text/plain; charset=utf-8"},
+		{"/plain", "mh=nin a)/eide", "text/plain; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+
+		if rec.Body.String() != tt.body {
+			t.Errorf("%s: expected body '%s', got '%s'", tt.path, tt.body, rec.Body.String())
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != tt.ctype {
+			t.Errorf("%s: expected Content-Type '%s', got '%s'", tt.path, tt.ctype, ct)
+		}
+	}
+}
+
+func TestHandlerStream(t *testing.T) {
+	rec := httptest.NewRecorder()
+	h := Handler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain; charset="+Charset)
+		fmt.Fprint(rw, "mh=nin a)")
+		if rec.Flushed {
+			t.Error("expected no Flush before the handler's")
+		}
+		rw.(http.Flusher).Flush()
+		if body := rec.Body.String(); body != "μῆνιν " || !rec.Flushed {
+			t.Errorf("expected 'μῆνιν ' to be flushed, got '%s'", body)
+		}
+		fmt.Fprint(rw, "/eide")
+	}))
+
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Body.String() != "μῆνιν ἄειδε" {
+		t.Error("expected 'μῆνιν ἄειδε', got '" + rec.Body.String() + "'")
+	}
+}
+
+// A plainResponse is a ResponseWriter without optional interfaces.
+type plainResponse struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (p *plainResponse) Header() http.Header         { return p.header }
+func (p *plainResponse) Write(b []byte) (int, error) { return p.body.Write(b) }
+func (p *plainResponse) WriteHeader(int)             {}
+
+func TestHandlerInterfaces(t *testing.T) {
+	var flush, hijack, push bool
+	h := Handler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, flush = rw.(http.Flusher)
+		_, hijack = rw.(http.Hijacker)
+		_, push = rw.(http.Pusher)
+	}))
+
+	h.ServeHTTP(&plainResponse{header: http.Header{}}, httptest.NewRequest("GET", "/", nil))
+	if flush || hijack || push {
+		t.Errorf("expected no optional interfaces, got Flusher %v, Hijacker %v, Pusher %v", flush, hijack, push)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !flush || hijack || push {
+		t.Errorf("expected a Flusher only, got Flusher %v, Hijacker %v, Pusher %v", flush, hijack, push)
+	}
+}
+
+func TestHandlerError(t *testing.T) {
+	h := Handler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain; charset="+Charset)
+		fmt.Fprint(rw, "lo/gos *")
+	}))
+
+	var logged bytes.Buffer
+	srv := &http.Server{ErrorLog: log.New(&logged, "", 0)}
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), http.ServerContextKey, srv))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if !strings.Contains(logged.String(), "incomplete") {
+		t.Errorf("expected the incomplete asterisk to be logged, got %q", logged.String())
+	}
+}
+
+func TestFormHandler(t *testing.T) {
+	h := FormHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, r.FormValue("title"), "|", r.FormValue("id"))
+	}), "title")
+
+	form := url.Values{"title": {"lo/gos"}, "id": {"a1"}}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "λόγος|a1" {
+		t.Error("expected 'λόγος|a1', got '" + rec.Body.String() + "'")
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("title", "qea/")
+	mw.WriteField("id", "b2")
+	mw.Close()
+	req = httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Body.String() != "θεά|b2" {
+		t.Error("expected 'θεά|b2' from a multipart form, got '" + rec.Body.String() + "'")
+	}
+
+	req = httptest.NewRequest("GET", "/?title=k/", nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Error("expected 400 for invalid Betacode, got", rec.Code)
+	}
+}
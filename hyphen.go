@@ -0,0 +1,102 @@
+package beta
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// The soft hyphen, an invisible hyphenation point
+const softHyphen = "\u00AD"
+
+// Pairs of consonants, in Betacode, that can begin a Greek word and thus a
+// syllable: a stop with a liquid or nasal, two stops, mu with nu and sigma
+// with a stop or mu.
+var initialPairs = map[string]bool{
+	"bl": true, "br": true, "bd": true,
+	"gl": true, "gr": true, "gn": true,
+	"dr": true, "dm": true, "dn": true,
+	"ql": true, "qr": true, "qn": true,
+	"kl": true, "kr": true, "kn": true, "kt": true,
+	"pl": true, "pr": true, "pn": true, "pt": true,
+	"tl": true, "tr": true, "tm": true,
+	"fl": true, "fr": true, "fn": true, "fq": true,
+	"xl": true, "xr": true, "xn": true, "xq": true,
+	"mn": true,
+	"sb": true, "sg": true, "sk": true, "sp": true, "st": true,
+	"sf": true, "sx": true, "sq": true, "sm": true,
+}
+
+// Hyphenate returns the Greek text with soft hyphens (U+00AD) at the
+// hyphenation points of its words, so that it can be justified in HTML or
+// LaTeX. Words are broken between syllables, as found by Syllables: before
+// a single consonant, before a group of consonants that can begin a word,
+// like the στ of ἐ-στί, and after the first consonant of any other group,
+// like ἄν-θρω-πος. The letters keep their diacritics and normalization;
+// everything but Greek letters is copied unchanged.
+func Hyphenate(greek string) string {
+	var sb strings.Builder
+	var clusters []string
+	var word []Sym
+
+	flush := func() {
+		breaks := hyphenation(word)
+		for i, c := range clusters {
+			if len(breaks) > 0 && breaks[0] == i {
+				sb.WriteString(softHyphen)
+				breaks = breaks[1:]
+			}
+			sb.WriteString(c)
+		}
+		clusters, word = clusters[:0], word[:0]
+	}
+
+	for len(greek) > 0 {
+		n := clusterLen(greek)
+		c := greek[:n]
+		greek = greek[n:]
+
+		sym, ok, err := clusterSym(norm.NFD.String(c))
+		if !ok || err != nil {
+			flush()
+			sb.WriteString(c)
+			continue
+		}
+		clusters = append(clusters, c)
+		word = append(word, sym)
+	}
+	flush()
+
+	return sb.String()
+}
+
+// hyphenation returns the indices of the symbols of word before which it
+// can be broken, ascending.
+func hyphenation(word []Sym) []int {
+	var breaks []int
+	n := nuclei(word)
+	for k := 1; k < len(n); k++ {
+		start, end := n[k-1][1], n[k][0]
+		i := start
+		for ; i < end-1; i++ {
+			if initialCluster(word[i:end]) {
+				break
+			}
+		}
+		breaks = append(breaks, i)
+	}
+	return breaks
+}
+
+// initialCluster reports whether the consonants cs can begin a syllable:
+// every pair of neighbours in it can begin a word.
+func initialCluster(cs []Sym) bool {
+	for i := 1; i < len(cs); i++ {
+		pair := string(unicode.ToLower(cs[i-1].Base)) + string(unicode.ToLower(cs[i].Base))
+		if !initialPairs[pair] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package beta
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHyphenate(t *testing.T) {
+	tests := []struct {
+		beta, hyphenated string
+	}{
+		{"a)/nqrwpos", "a)/n-qrw-pos"},
+		{"qea/", "qe-a/"},
+		{"lo/gos", "lo/-gos"},
+		{"e)sti/n", "e)-sti/n"},
+		{"a)/llos", "a)/l-los"},
+		{"poiei=n", "poi-ei=n"},
+		{"Phlhi+a/dew", "Ph-lh-i+-a/-de-w"},
+		{"e)xqro/s", "e)-xqro/s"},
+		{"a)/mpelos", "a)/m-pe-los"},
+		{"mh=nin, a)/eide.", "mh=-nin, a)/-ei-de."},
+	}
+
+	for _, tt := range tests {
+		greek := MustToGreek(tt.beta)
+		ref := strings.ReplaceAll(MustToGreek(strings.ReplaceAll(tt.hyphenated, "-", "{L-L}")), "-", "\u00AD")
+		if s := Hyphenate(greek); s != ref {
+			t.Errorf("%s: expected %q, got %q", tt.beta, ref, s)
+		}
+	}
+
+	// Combining diacritics stay as they are.
+	combining := "\u03BB\u03BF\u0301\u03B3\u03BF\u03C2"
+	if s := Hyphenate(combining); s != "\u03BB\u03BF\u0301\u00AD\u03B3\u03BF\u03C2" {
+		t.Errorf("unexpected %q", s)
+	}
+}
@@ -0,0 +1,78 @@
+package beta
+
+import (
+	"errors"
+	"strings"
+)
+
+// Syllables returns the number of syllables of the word, that is, of its
+// vowels and diphthongs.
+func (w Word) Syllables() int {
+	return len(nuclei(w.Syms))
+}
+
+// Accent returns the first accent of the word, /, \ or =, and its
+// syllable, counted from the end of the word as in grammars: 1 is the
+// ultima, 2 the penult, 3 the antepenult. Without accent, it returns 0, 0.
+func (w Word) Accent() (syllable int, accent rune) {
+	n := nuclei(w.Syms)
+	for k, nuc := range n {
+		for i := nuc[0]; i < nuc[1]; i++ {
+			if a := w.Syms[i].Accent; a != 0 {
+				return len(n) - k, a
+			}
+		}
+	}
+	return 0, 0
+}
+
+// AddAccent returns the word with accent, /, \ or =, on the syllable
+// counted from the end, as by Accent. On a diphthong, the accent goes on
+// the second vowel. Other accents of the word are kept.
+func (w Word) AddAccent(syllable int, accent rune) (Word, error) {
+	if accent != '/' && accent != '\\' && accent != '=' {
+		return w, errors.New("invalid accent " + string(accent))
+	}
+
+	n := nuclei(w.Syms)
+	if syllable < 1 || syllable > len(n) {
+		return w, errors.New("no such syllable")
+	}
+
+	syms := append([]Sym(nil), w.Syms...)
+	sym := &syms[n[len(n)-syllable][1]-1]
+	sym.Accent = accent
+	if err := strictErr(*sym); err != nil {
+		return w, err
+	}
+	return w.withSyms(syms), nil
+}
+
+// RemoveAccents returns the word without accents. Breathings are kept.
+func (w Word) RemoveAccents() Word {
+	syms := append([]Sym(nil), w.Syms...)
+	for i := range syms {
+		syms[i].Accent = 0
+	}
+	return w.withSyms(syms)
+}
+
+// MoveAccent returns the word with its accents removed and the first one
+// put on the syllable counted from the end, as by Accent.
+func (w Word) MoveAccent(syllable int) (Word, error) {
+	_, accent := w.Accent()
+	if accent == 0 {
+		return w, errors.New("word has no accent")
+	}
+	return w.RemoveAccents().AddAccent(syllable, accent)
+}
+
+// withSyms returns the word with syms and a Source to match, spelt as by
+// Sym.String.
+func (w Word) withSyms(syms []Sym) Word {
+	var sb strings.Builder
+	for _, sym := range syms {
+		sb.WriteString(sym.String())
+	}
+	return Word{Source: sb.String(), Pos: w.Pos, Syms: syms}
+}
@@ -0,0 +1,50 @@
+package beta
+
+import "testing"
+
+func TestAccents(t *testing.T) {
+	words, err := Words("a)/nqrwpos lo/gou paidi/on")
+	if err != nil {
+		t.Fatal(err)
+	}
+	anthropos, logou, paidion := words[0], words[1], words[2]
+
+	if n := anthropos.Syllables(); n != 3 {
+		t.Errorf("expected 3 syllables, got %d", n)
+	}
+	if n := logou.Syllables(); n != 2 {
+		t.Errorf("expected 2 syllables, got %d", n)
+	}
+	if syl, acc := paidion.Accent(); syl != 2 || acc != '/' {
+		t.Errorf("expected acute on the penult, got %c on %d", acc, syl)
+	}
+
+	w, err := anthropos.MoveAccent(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Source != "a)nqrw/poj" || w.Greek() != "ἀνθρώπος" {
+		t.Errorf("unexpected %q (%s)", w.Source, w.Greek())
+	}
+	if anthropos.Source != "a)/nqrwpos" || anthropos.Syms[0].Accent != '/' {
+		t.Error("MoveAccent changed the original word")
+	}
+
+	w, err = logou.RemoveAccents().AddAccent(1, '=')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Source != "logou=" {
+		t.Errorf("expected logou=, got %q", w.Source)
+	}
+
+	if _, err := logou.RemoveAccents().AddAccent(2, '='); err == nil {
+		t.Error("expected error for circumflex on omicron")
+	}
+	if _, err := logou.AddAccent(3, '/'); err == nil {
+		t.Error("expected error for missing syllable")
+	}
+	if _, err := logou.RemoveAccents().MoveAccent(1); err == nil {
+		t.Error("expected error for word without accent")
+	}
+}
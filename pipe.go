@@ -0,0 +1,48 @@
+package beta
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrClosed is returned by writes to a closed Pipe.
+var ErrClosed = errors.New("beta: write to closed pipe")
+
+// Pipe returns a writer that converts the Betacode written to it with opts
+// and writes the Greek to dst. Unlike a Writer, it passes on all converted
+// output before a Write returns, so a slow dst slows down the writes: it
+// can be fed from an upload as it is received, or feed the write end of an
+// io.Pipe. Only a symbol or a part of a UTF-8 sequence at the end of a
+// Write is held back until the next Write or Close. Close writes it out, but doesn't close dst.
+func Pipe(dst io.Writer, opts Options) io.WriteCloser {
+	w := NewWriter(dst)
+	w.Options = opts
+	return &pipe{w: w}
+}
+
+type pipe struct {
+	w      *Writer
+	closed bool
+}
+
+func (p *pipe) Write(b []byte) (int, error) {
+	if p.closed {
+		return 0, ErrClosed
+	}
+
+	// The output up to an error is passed on as well.
+	n, err := p.w.Write(b)
+	if ferr := p.w.flushBuf(); ferr != nil && err == nil {
+		p.w.err = ferr
+		err = ferr
+	}
+	return n, err
+}
+
+func (p *pipe) Close() error {
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	return p.w.Flush()
+}
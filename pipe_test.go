@@ -0,0 +1,54 @@
+package beta
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestPipe(t *testing.T) {
+	pr, pw := io.Pipe()
+	bw := Pipe(pw, Options{})
+
+	done := make(chan string)
+	go func() {
+		p, _ := ioutil.ReadAll(pr)
+		done <- string(p)
+	}()
+
+	// Each Write is passed on before it returns; the io.Pipe would block
+	// otherwise.
+	for _, s := range []string{"Mh=nin a)/", "eide, qea/, ", "lo/gos"} {
+		if _, err := io.WriteString(bw, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	pw.Close()
+
+	if s := <-done; s != "Μῆνιν ἄειδε, θεά, λόγος" {
+		t.Errorf("unexpected %q", s)
+	}
+
+	if _, err := bw.Write([]byte("a")); err != ErrClosed {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestPipeError(t *testing.T) {
+	var sb strings.Builder
+	bw := Pipe(&sb, Options{})
+
+	if _, err := io.WriteString(bw, "qea/ k/ai"); err == nil {
+		t.Error("expected error for invalid Betacode")
+	}
+	if err := bw.Close(); err == nil {
+		t.Error("expected the error again from Close")
+	}
+	if sb.String() != "θεά " {
+		t.Errorf("unexpected %q", sb.String())
+	}
+}
@@ -0,0 +1,287 @@
+package beta
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Position is a location in Betacode source.
+type Position struct {
+	Offset int // Byte offset, starting at 0
+	Line   int // Line number, starting at 1
+	Col    int // Column in runes, starting at 1
+}
+
+// String returns the position as line:col.
+func (pos Position) String() string {
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Col)
+}
+
+// advance moves pos past r.
+func (pos *Position) advance(r rune) {
+	pos.advanceSize(r, utf8.RuneLen(r))
+}
+
+// advanceSize moves pos past r, which takes size bytes of input. Invalid
+// UTF-8 is decoded as U+FFFD, which is longer.
+func (pos *Position) advanceSize(r rune, size int) {
+	pos.Offset += size
+	if r == '\n' {
+		pos.Line++
+		pos.Col = 1
+	} else {
+		pos.Col++
+	}
+}
+
+// Severity tells how serious a Diagnostic is.
+type Severity int
+
+const (
+	Warning Severity = iota // Unusual, but convertible
+	Error                   // Not convertible
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// A Diagnostic is a message about a word in Betacode source.
+type Diagnostic struct {
+	Pos      Position // Start of the word, or the offending rune for Options.Warn
+	Word     string   // Betacode source of the word, or of the symbol
+	Context  string   // Betacode source of the whole word, if Word is a symbol of it
+	Greek    string   // Greek of the word before the error, if any
+	Msg      string
+	Severity Severity
+}
+
+// String formats the diagnostic as line:col: message: word.
+func (d Diagnostic) String() string {
+	return d.Pos.String() + ": " + d.Msg + ": " + d.Word
+}
+
+// Diphthongs in Betacode. The diacritics go on the second vowel.
+var diphthongs = []string{"ai", "ei", "oi", "ui", "au", "eu", "ou", "hu", "wu"}
+
+// diphthong reports whether a and b form a diphthong.
+func diphthong(a, b Sym) bool {
+	if b.Trema || a.Iota || a.Accent != 0 || a.Spiritus != 0 && !unicode.IsUpper(a.Base) {
+		return false
+	}
+
+	return isDiphthong(a.Base, b.Base)
+}
+
+// isDiphthong reports whether the letters a and b form a diphthong.
+func isDiphthong(a, b rune) bool {
+	pair := string(unicode.ToLower(a)) + string(unicode.ToLower(b))
+	for _, d := range diphthongs {
+		if pair == d {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nuclei returns the vowels or diphthongs of word as [start, end) index pairs.
+func nuclei(word []Sym) [][2]int {
+	var n [][2]int
+
+	for i := 0; i < len(word); i++ {
+		if !vowel(word[i].Base) {
+			continue
+		}
+
+		if i+1 < len(word) && vowel(word[i+1].Base) && diphthong(word[i], word[i+1]) {
+			n = append(n, [2]int{i, i + 2})
+			i++
+		} else {
+			n = append(n, [2]int{i, i + 1})
+		}
+	}
+
+	return n
+}
+
+// parseWord parses a word of Betacode characters into symbols. next is the
+// rune after the word, or 0 at the end of input; it decides whether a
+// trailing sigma becomes final sigma.
+func parseWord(word string, next rune) ([]Sym, error) {
+	var syms []Sym
+	var sym Sym
+
+	for _, r := range word {
+		if sym.Add(r) {
+			continue
+		}
+		if sym.Err() != nil {
+			return syms, sym.Err()
+		}
+
+		syms = append(syms, sym)
+		sym.Reset()
+		if !sym.Add(r) {
+			return syms, sym.Err()
+		}
+	}
+
+	if sym.Base != 0 {
+		if sym.Base == 's' && finalSigma(next) {
+			sym.Base = 'j'
+		}
+		syms = append(syms, sym)
+	} else if !sym.Empty() {
+		return syms, fmt.Errorf("asterisk without base character")
+	}
+
+	return syms, nil
+}
+
+// proofWord returns the linguistic problems of a parsed word.
+func proofWord(word []Sym) []string {
+	var msgs []string
+
+	n := nuclei(word)
+	if len(n) == 0 {
+		return nil
+	}
+
+	// Breathing belongs on an initial vowel or diphthong or on rho.
+	first := n[0]
+	if first[0] == 0 {
+		hasBreathing := false
+		for i := first[0]; i < first[1]; i++ {
+			if word[i].Spiritus != 0 {
+				hasBreathing = true
+			}
+		}
+		if !hasBreathing {
+			msgs = append(msgs, "missing breathing on initial vowel")
+		}
+	}
+	for i, sym := range word {
+		initial := first[0] == 0 && i < first[1]
+		// A smooth breathing inside a word is a koronis.
+		if sym.Spiritus == '(' && !initial && unicode.ToLower(sym.Base) != 'r' {
+			msgs = append(msgs, "rough breathing on non-initial vowel")
+		}
+	}
+
+	// Accents: at most two (the second from an enclitic), acute on the
+	// last three syllables, circumflex on the last two, grave on the last.
+	accents := 0
+	for k, nuc := range n {
+		fromEnd := len(n) - k
+		for i := nuc[0]; i < nuc[1]; i++ {
+			a := word[i].Accent
+			if a == 0 {
+				continue
+			}
+			accents++
+
+			switch {
+			case a == '/' && fromEnd > 3:
+				msgs = append(msgs, "acute before the antepenult")
+			case a == '=' && fromEnd > 2:
+				msgs = append(msgs, "circumflex before the penult")
+			case a == '\\' && fromEnd > 1:
+				msgs = append(msgs, "grave before the ultima")
+			case accents == 2 && (a != '/' || fromEnd != 1):
+				msgs = append(msgs, "second accent not an acute on the ultima")
+			}
+		}
+	}
+	if accents > 2 {
+		msgs = append(msgs, "more than two accents")
+	}
+
+	// Iota subscript is only written under long alpha, eta and omega.
+	for _, sym := range word {
+		if sym.Iota && !strings.ContainsRune("ahw", unicode.ToLower(sym.Base)) {
+			msgs = append(msgs, "iota subscript on vowel other than alpha, eta or omega")
+		}
+	}
+
+	// A diaeresis separates a vowel from the preceding one.
+	for i, sym := range word {
+		if !sym.Trema {
+			continue
+		}
+		if !strings.ContainsRune("iu", unicode.ToLower(sym.Base)) {
+			msgs = append(msgs, "diaeresis on vowel other than iota or upsilon")
+		} else if i == 0 || !vowel(word[i-1].Base) {
+			msgs = append(msgs, "diaeresis not after a vowel")
+		}
+	}
+
+	return msgs
+}
+
+// Proof runs linguistic checks over Betacode source and returns warnings about
+// unusual words: missing or misplaced breathings, impossible accent positions
+// and misplaced diaereses. Words that fail to parse are reported as errors.
+// Proof is a proofreading aid; a warning is not necessarily a mistake.
+func Proof(src string) []Diagnostic {
+	var diags []Diagnostic
+
+	scanPieces(Options{}, src, nil, func(p piece) bool {
+		if p.kind != pieceWord {
+			return true
+		}
+		w := src[p.in[0]:p.in[1]]
+		if p.err != nil {
+			diags = append(diags, Diagnostic{Pos: p.pos, Word: w, Greek: string(p.text), Msg: p.err.Error(), Severity: Error})
+			return true
+		}
+		for _, msg := range proofWord(p.syms) {
+			diags = append(diags, Diagnostic{Pos: p.pos, Word: w, Msg: msg})
+		}
+		return true
+	})
+	return diags
+}
+
+// WordContext returns the Betacode word of src that contains the byte
+// offset, or ends at it, and the Greek of the word before the offset, read
+// as by a Decoder with opts, for the context of a diagnostic about the rune
+// at offset, like an error of a Decoder with opts over src. If there is no
+// word at offset, both are empty.
+func WordContext(src string, offset int, opts Options) (word, greek string) {
+	scanPieces(opts, src, nil, func(p piece) bool {
+		if p.kind != pieceWord || p.in[1] < offset {
+			return p.in[0] <= offset
+		}
+		if p.in[0] > offset {
+			return false
+		}
+
+		word = src[p.in[0]:p.in[1]]
+		if offset == p.in[1] {
+			greek = string(p.text)
+			return false
+		}
+
+		// The word goes on after the offset, so a sigma before it is
+		// medial.
+		prefix := opts
+		if prefix.Sigma == SigmaAuto {
+			prefix.Sigma = SigmaExplicit
+		}
+		scanPieces(prefix, src[p.in[0]:offset], nil, func(p piece) bool {
+			if p.kind != pieceWord {
+				return true
+			}
+			greek = string(p.text)
+			return false
+		})
+		return false
+	})
+	return word, greek
+}
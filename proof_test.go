@@ -0,0 +1,121 @@
+package beta
+
+import "testing"
+
+func TestProof(t *testing.T) {
+	tests := []struct {
+		src  string
+		msgs []string
+	}{
+		{"mh=nin a)/eide, qea/, Phlhi+a/dew A)xilh=os", nil},
+		{"ai)/c ou)rano/s kai/ tis", nil},
+		{"aeide", []string{"missing breathing on initial vowel"}},
+		{"a)nqrw(pos", []string{"rough breathing on non-initial vowel"}},
+		{"a)/nqrwpoisin", []string{"acute before the antepenult"}},
+		{"a)=nqrwpoi", []string{"circumflex before the penult"}},
+		{"lo\\gos", []string{"grave before the ultima"}},
+		{"a)/nqrwpo/s", nil},
+		{"a)/nqrwpo\\s", []string{"second accent not an acute on the ultima"}},
+		{"pa+", []string{"diaeresis on vowel other than iota or upsilon"}},
+		{"li+", []string{"diaeresis not after a vowel"}},
+		{"lo/go|s", []string{"iota subscript on vowel other than alpha, eta or omega"}},
+		{"w)|dh=|", nil},
+		{"k/", []string{"can't put accent on non-vowels"}},
+	}
+
+	for _, tt := range tests {
+		diags := Proof(tt.src)
+		if len(diags) != len(tt.msgs) {
+			t.Errorf("%q: expected %v, got %v", tt.src, tt.msgs, diags)
+			continue
+		}
+		for i, d := range diags {
+			if d.Msg != tt.msgs[i] {
+				t.Errorf("%q: expected '%s', got '%s'", tt.src, tt.msgs[i], d.Msg)
+			}
+		}
+	}
+}
+
+func TestProofPosition(t *testing.T) {
+	diags := Proof("mh=nin\nkai/ aeide")
+	if len(diags) != 1 {
+		t.Fatal("expected one diagnostic, got", diags)
+	}
+
+	d := diags[0]
+	if d.Word != "aeide" || d.Pos != (Position{Offset: 12, Line: 2, Col: 6}) {
+		t.Error("expected aeide at 2:6, offset 12, got", d.Word, "at", d.Pos, "offset", d.Pos.Offset)
+	}
+}
+
+func TestProofLiteral(t *testing.T) {
+	if diags := Proof("qea/ {Lsee aeide aboveL} lo/gos"); len(diags) != 0 {
+		t.Error("expected no diagnostics for literal region, got", diags)
+	}
+}
+
+func TestWordContext(t *testing.T) {
+	tests := []struct {
+		src     string
+		offset  int
+		dialect Dialect
+		word    string
+		greek   string
+	}{
+		{"kai\\ lo/ga/\\s", 11, DialectTypeGreek, "lo/ga/\\s", "\u03BB\u03CC\u03B3\u03AC"},
+		{"lo/gos", 3, DialectTypeGreek, "lo/gos", "\u03BB\u03CC"},
+		{"lo/gos kai\\", 6, DialectTypeGreek, "lo/gos", "\u03BB\u03CC\u03B3\u03BF\u03C2"},
+		{"*A)/NQRWPOS", 5, DialectTLG, "*A)/NQRWPOS", "\u1F0C\u03BD"},
+		{"kai\\ lo/gos", 4, DialectTypeGreek, "kai\\", "\u03BA\u03B1\u1F76"},
+		{"kai\\ ", 5, DialectTypeGreek, "", ""},
+		{"{Llo/gosL}", 4, DialectTypeGreek, "", ""},
+	}
+
+	for _, tt := range tests {
+		word, greek := WordContext(tt.src, tt.offset, Options{Dialect: tt.dialect})
+		if word != tt.word || greek != tt.greek {
+			t.Errorf("%q at %d: expected %q, %q, got %q, %q", tt.src, tt.offset, tt.word, tt.greek, word, greek)
+		}
+	}
+
+	opts := Options{LiteralOpen: "<<", LiteralClose: ">>"}
+	if word, _ := WordContext("<<lo/gos>> {Lkai\\", 3, opts); word != "" {
+		t.Errorf("expected no word in the literal region, got %q", word)
+	}
+	if word, _ := WordContext("<<lo/gos>> {Lkai\\", 14, opts); word != "Lkai\\" {
+		t.Errorf("expected Lkai\\, got %q", word)
+	}
+}
+
+func TestProofSeverity(t *testing.T) {
+	tests := []struct {
+		src   string
+		sevs  []Severity
+		words []string
+	}{
+		{"aeide", []Severity{Warning}, []string{"aeide"}},
+		{"lo/gk/os", []Severity{Error}, []string{"lo/gk/os"}},
+		{"aeide k/ lo\\gos", []Severity{Warning, Error, Warning}, []string{"aeide", "k/", "lo\\gos"}},
+	}
+
+	for _, tt := range tests {
+		diags := Proof(tt.src)
+		if len(diags) != len(tt.sevs) {
+			t.Errorf("%q: expected %d diagnostics, got %v", tt.src, len(tt.sevs), diags)
+			continue
+		}
+		for i, d := range diags {
+			if d.Severity != tt.sevs[i] || d.Word != tt.words[i] {
+				t.Errorf("%q: expected %s for %q, got %s for %q", tt.src, tt.sevs[i], tt.words[i], d.Severity, d.Word)
+			}
+		}
+	}
+}
+
+func TestProofErrorGreek(t *testing.T) {
+	diags := Proof("lo/gk/os")
+	if len(diags) != 1 || diags[0].Severity != Error || diags[0].Greek != "\u03BB\u03CC\u03B3" {
+		t.Error("expected an error after the Greek \u03BB\u03CC\u03B3, got", diags)
+	}
+}
@@ -0,0 +1,91 @@
+package beta
+
+import (
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+// Letters of RandomBetacode, without final sigma and digamma
+const (
+	randomConsonants = "bgdzqklmncprstfxy"
+	randomVowels     = "aehiouw"
+)
+
+// RandomBetacode returns n random words of TypeGreek Betacode, separated by
+// spaces, punctuation and line breaks, for property tests of pipelines that
+// read Betacode. The words are valid at LevelPedantic and in canonical form,
+// so that FromGreek converts their Greek back to them unchanged. The same
+// source of r yields the same Betacode.
+func RandomBetacode(r *rand.Rand, n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			switch p := r.Intn(20); {
+			case p == 0:
+				sb.WriteString(".\n")
+			case p < 3:
+				sb.WriteString(", ")
+			default:
+				sb.WriteByte(' ')
+			}
+		}
+		for _, sym := range randomWord(r) {
+			sb.WriteString(sym.String())
+		}
+	}
+	return sb.String()
+}
+
+// randomWord returns a random word of RandomBetacode.
+func randomWord(r *rand.Rand) []Sym {
+	word := make([]Sym, 1+r.Intn(8))
+	var vowels []int
+	for i := range word {
+		// Mostly alternate consonants and vowels.
+		v := i > 0 && !vowel(word[i-1].Base)
+		if i == 0 || r.Intn(5) == 0 {
+			v = r.Intn(2) == 0
+		}
+		// No initial diphthong, no three vowels in a row.
+		if i > 0 && vowel(word[i-1].Base) && (i == 1 || vowel(word[i-2].Base)) {
+			v = false
+		}
+
+		if !v {
+			word[i].Base = rune(randomConsonants[r.Intn(len(randomConsonants))])
+			continue
+		}
+		word[i].Base = rune(randomVowels[r.Intn(len(randomVowels))])
+		vowels = append(vowels, i)
+
+		b := word[i].Base
+		if strings.ContainsRune("ahw", b) && r.Intn(10) == 0 {
+			word[i].Iota = true
+		}
+		if i > 0 && (b == 'i' || b == 'u') && vowel(word[i-1].Base) && r.Intn(5) == 0 {
+			word[i].Trema = true
+		}
+	}
+
+	switch {
+	case vowel(word[0].Base):
+		word[0].Spiritus = rune(")("[r.Intn(2)])
+	case word[0].Base == 'r' && r.Intn(2) == 0:
+		word[0].Spiritus = '('
+	}
+
+	if len(vowels) > 0 {
+		sym := &word[vowels[r.Intn(len(vowels))]]
+		accents := `/\=`
+		if sym.Base == 'e' || sym.Base == 'o' {
+			accents = `/\`
+		}
+		sym.Accent = rune(accents[r.Intn(len(accents))])
+	}
+
+	if r.Intn(10) == 0 {
+		word[0].Base = unicode.ToUpper(word[0].Base)
+	}
+	return word
+}
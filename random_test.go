@@ -0,0 +1,28 @@
+package beta
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRandomBetacode(t *testing.T) {
+	a := RandomBetacode(rand.New(rand.NewSource(1)), 50)
+	if b := RandomBetacode(rand.New(rand.NewSource(1)), 50); a != b {
+		t.Errorf("same seed, different Betacode: %q, %q", a, b)
+	}
+
+	for seed := int64(0); seed < 100; seed++ {
+		src := RandomBetacode(rand.New(rand.NewSource(seed)), 20)
+		greek, err := ConvertAppend(nil, []byte(src), Options{Level: LevelPedantic})
+		if err != nil {
+			t.Fatalf("%q: %v", src, err)
+		}
+		back, err := FromGreek(string(greek))
+		if err != nil {
+			t.Fatalf("%q: %v", greek, err)
+		}
+		if back != src {
+			t.Fatalf("%q round trips to %q", src, back)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package beta
+
+import (
+	"bufio"
+	"io"
+)
+
+// A Reader converts Betacode read from an underlying reader to UTF-8 Greek.
+//
+// On invalid Betacode, Read returns the Greek converted up to the error and
+// then, on the next call, the *SyntaxError. Errors are sticky: once Read has
+// returned an error, all further Reads return it. Use Options.Handler or
+// Options.Recovery to go on after invalid Betacode instead.
+type Reader struct {
+	Options
+
+	dec Decoder
+	r   *bufio.Reader
+	buf []byte
+	out []byte // Converted, but not read yet
+	err error  // Returned once out is read
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read reads converted Greek into p. At the end of the input, the held-back
+// symbol is emitted as the end of a word, and the error is io.EOF.
+func (r *Reader) Read(p []byte) (n int, err error) {
+	for len(r.out) == 0 && r.err == nil {
+		r.fill()
+	}
+
+	n = copy(p, r.out)
+	r.out = r.out[n:]
+	if n > 0 || len(p) == 0 {
+		return n, nil
+	}
+	return 0, r.err
+}
+
+// fill converts the input until some output is ready, the buffered input is
+// used up or an error occurs.
+func (r *Reader) fill() {
+	r.dec.Options = r.Options
+	r.buf = r.buf[:0]
+
+	for len(r.buf) < 512 {
+		c, size, err := r.r.ReadRune()
+		if err == io.EOF {
+			r.buf, err = r.dec.end(r.buf)
+			if err == nil {
+				err = io.EOF
+			}
+		}
+		if err != nil {
+			r.err = err
+			break
+		}
+
+		if r.buf, err = r.dec.pushSize(r.buf, c, size); err != nil {
+			r.err = err
+			break
+		}
+		if r.r.Buffered() == 0 {
+			break
+		}
+	}
+
+	r.out = r.buf
+}
@@ -0,0 +1,50 @@
+package beta
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	const ref = `Μῆνιν ἄειδε, θεά, Πηληϊάδεω Ἀχιλῆος`
+
+	r := NewReader(iotest.OneByteReader(strings.NewReader("Mh=nin a)/eide, qea/, Phlhi+a/dew A)xilh=os")))
+	p, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p) != ref {
+		t.Error("expected '" + ref + "', got '" + string(p) + "'")
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	r := NewReader(strings.NewReader("qea/ k/ai"))
+	p := make([]byte, 64)
+
+	n, err := r.Read(p)
+	if err != nil || string(p[:n]) != "θεά " {
+		t.Errorf("expected 'θεά ' without error, got %q, %v", p[:n], err)
+	}
+
+	n, err = r.Read(p)
+	serr, ok := err.(*SyntaxError)
+	if n != 0 || !ok || serr.Pos.String() != "1:7" {
+		t.Errorf("expected SyntaxError at 1:7, got %d, %v", n, err)
+	}
+	if _, err2 := r.Read(p); err2 != err {
+		t.Errorf("expected sticky error %v, got %v", err, err2)
+	}
+
+	r = NewReader(strings.NewReader("qea/ k/ai"))
+	r.Recovery = RecoverVerbatim
+	if p, err := ioutil.ReadAll(r); err != nil || string(p) != "θεά k/ai" {
+		t.Errorf("expected 'θεά k/ai' with recovery, got %q, %v", p, err)
+	}
+	if _, err := r.Read(p); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package beta
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Rule substitutes a character of the Betacode input, for the quirks of a
+// corpus: From is read as the Betacode To, which may be empty to drop it,
+// or, if Mark is not empty, as a diacritic of the symbol before it, whose
+// Greek is followed by Mark, like a combining dot below. Marks are not
+// passed to a Sink.
+type Rule struct {
+	From rune
+	To   string
+	Mark string
+}
+
+// ParseRules reads Rules, one per line: the character, followed by the
+// Betacode it is read as, or by "mark" and the mark. The character and the
+// mark may also be written in the form U+0323, as # must be, which would
+// start a comment: blank lines and lines starting with # are ignored. A
+// character alone is dropped. For example:
+//
+//	# v is dropped, ! is a dot below, & and # are sigmas
+//	v
+//	! mark U+0323
+//	& s
+//	U+0023 s
+func ParseRules(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		c, err := unescape(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		from, n := utf8.DecodeRuneInString(c)
+		if n != len(c) {
+			return nil, fmt.Errorf("line %d: %q is not a single character", line, fields[0])
+		}
+		rule := Rule{From: from}
+
+		switch {
+		case len(fields) == 1:
+		case len(fields) == 2:
+			rule.To = fields[1]
+		case len(fields) == 3 && fields[1] == "mark":
+			mark, err := unescape(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			rule.Mark = mark
+		default:
+			return nil, fmt.Errorf("line %d: invalid rule %q", line, sc.Text())
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, sc.Err()
+}
+
+// unescape returns the characters s or, if s is of the form U+0323, the
+// character it stands for.
+func unescape(s string) (string, error) {
+	if !strings.HasPrefix(s, "U+") {
+		return s, nil
+	}
+
+	v, err := strconv.ParseUint(s[2:], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(v)) {
+		return "", fmt.Errorf("invalid character %q", s)
+	}
+	return string(rune(v)), nil
+}
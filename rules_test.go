@@ -0,0 +1,58 @@
+package beta
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	const src = "# quirks\nv\n\n! mark U+0323\n& s\nU+0023 s3\n"
+
+	rules, err := ParseRules(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := []Rule{{From: 'v'}, {From: '!', Mark: "\u0323"}, {From: '&', To: "s"}, {From: '#', To: "s3"}}
+	if len(rules) != len(ref) {
+		t.Fatalf("expected %v, got %v", ref, rules)
+	}
+	for i := range ref {
+		if rules[i] != ref[i] {
+			t.Errorf("expected %v, got %v", ref[i], rules[i])
+		}
+	}
+
+	for _, bad := range []string{"ab", "! mark U+ZZ", "a b c", "U+ZZ s", "U+00230023 s"} {
+		if _, err := ParseRules(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestRules(t *testing.T) {
+	rules := []Rule{{From: 'v'}, {From: '!', Mark: "\u0323"}, {From: '&', To: "s"}, {From: '^', To: "a)/"}}
+
+	tests := []struct {
+		beta, greek string
+	}{
+		{"vo/los", "\u03CC\u03BB\u03BF\u03C2"},
+		{"lo!/go&", "\u03BB\u03CC\u0323\u03B3\u03BF\u03C2"},
+		{"^nqrwpos", "\u1F04\u03BD\u03B8\u03C1\u03C9\u03C0\u03BF\u03C2"},
+		{"{Lv!L}", "v!"},
+	}
+
+	for _, tt := range tests {
+		d := Decoder{Options: Options{Rules: rules}}
+		s, err := d.convert(nil, tt.beta)
+		if err != nil {
+			t.Errorf("%q: %v", tt.beta, err)
+		} else if string(s) != tt.greek {
+			t.Errorf("%q: expected %+q, got %+q", tt.beta, tt.greek, s)
+		}
+	}
+
+	d := Decoder{Options: Options{Rules: rules}}
+	if _, err := d.convert(nil, "a !"); err == nil {
+		t.Error("expected error for mark without letter")
+	}
+}
@@ -0,0 +1,51 @@
+package beta
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Combining diacritics of polytonic Greek.
+const (
+	grave      = '\u0300'
+	acute      = '\u0301'
+	circumflex = '\u0342'
+	smooth     = '\u0313'
+	rough      = '\u0314'
+)
+
+// decompose returns the canonical decomposition of r.
+func decompose(r rune) string {
+	return norm.NFD.String(string(r))
+}
+
+// HasAccent reports whether r, a precombined or combining character, has an
+// acute, grave or circumflex accent (or a tonos).
+func HasAccent(r rune) bool {
+	for _, m := range decompose(r) {
+		switch m {
+		case grave, acute, circumflex:
+			return true
+		}
+	}
+	return false
+}
+
+// Breathing returns the combining smooth or rough breathing (U+0313 or
+// U+0314) of the precombined character r, or 0 if it has none.
+func Breathing(r rune) rune {
+	for _, m := range decompose(r) {
+		if m == smooth || m == rough {
+			return m
+		}
+	}
+	return 0
+}
+
+// BaseLetter returns r without its diacritics, e.g. α for ᾄ. Characters
+// without diacritics are returned unchanged.
+func BaseLetter(r rune) rune {
+	b, _ := utf8.DecodeRuneInString(decompose(r))
+	return b
+}
@@ -0,0 +1,32 @@
+package beta
+
+import "testing"
+
+func TestRuneProperties(t *testing.T) {
+	tests := []struct {
+		r         rune
+		accent    bool
+		breathing rune
+		base      rune
+	}{
+		{'\u1F84', true, '\u0313', '\u03B1'},  // ᾄ
+		{'\u1F09', false, '\u0314', '\u0391'}, // Ἁ
+		{'\u03AC', true, 0, '\u03B1'},         // ά with tonos
+		{'\u1FE4', false, '\u0313', '\u03C1'}, // ῤ
+		{'\u03C2', false, 0, '\u03C2'},
+		{'\u0342', true, 0, '\u0342'},
+		{'x', false, 0, 'x'},
+	}
+
+	for _, tt := range tests {
+		if a := HasAccent(tt.r); a != tt.accent {
+			t.Errorf("HasAccent(%U): expected %v", tt.r, tt.accent)
+		}
+		if b := Breathing(tt.r); b != tt.breathing {
+			t.Errorf("Breathing(%U): expected %U, got %U", tt.r, tt.breathing, b)
+		}
+		if b := BaseLetter(tt.r); b != tt.base {
+			t.Errorf("BaseLetter(%U): expected %U, got %U", tt.r, tt.base, b)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package beta
+
+import "io"
+
+// A Sink receives the output of a Writer as Greek symbols and raw text
+// rather than as UTF-8, for backends like HTML, LaTeX or legacy encodings
+// that share the parsing of Betacode. The symbols have the Options applied:
+// a final sigma is j, and with Caps and Plain, the diacritics are removed
+// and an iota adscript is a separate capital I.
+type Sink interface {
+	// WriteSymbol writes a Greek letter with its diacritics.
+	WriteSymbol(sym Sym) error
+
+	// WriteBoundary marks the end of a word, before the raw text that
+	// ends it.
+	WriteBoundary() error
+
+	// WriteRaw writes text that isn't Betacode, like whitespace,
+	// punctuation, literal regions and words copied by RecoverVerbatim.
+	WriteRaw(p []byte) error
+}
+
+// A TextSink writes the symbols as UTF-8 Greek to W, as a Writer does.
+type TextSink struct {
+	W io.Writer
+
+	// The form of the symbols, as in Options.
+	Combining, Canonical bool
+
+	buf []byte
+}
+
+func (s *TextSink) WriteSymbol(sym Sym) error {
+	if s.Combining {
+		s.buf = sym.appendCombining(s.buf[:0], s.Canonical)
+	} else {
+		s.buf = append(s.buf[:0], precombined(sym, s.Canonical)...)
+	}
+	_, err := s.W.Write(s.buf)
+	return err
+}
+
+func (s *TextSink) WriteBoundary() error {
+	return nil
+}
+
+func (s *TextSink) WriteRaw(p []byte) error {
+	_, err := s.W.Write(p)
+	return err
+}
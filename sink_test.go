@@ -0,0 +1,85 @@
+package beta
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// recorder is a Sink that writes its calls: symbols in angle brackets and
+// boundaries as |.
+type recorder struct {
+	strings.Builder
+}
+
+func (r *recorder) WriteSymbol(sym Sym) error {
+	r.WriteString("<" + sym.String() + ">")
+	return nil
+}
+
+func (r *recorder) WriteBoundary() error {
+	r.WriteString("|")
+	return nil
+}
+
+func (r *recorder) WriteRaw(p []byte) error {
+	r.Write(p)
+	return nil
+}
+
+func TestSinkWriter(t *testing.T) {
+	tests := []struct {
+		opts       Options
+		beta, want string
+	}{
+		{Options{}, "lo/gos, {Lp. 5L}qea/", "<l><o/><g><o><j>|, p. 5<q><e><a/>|"},
+		{Options{Caps: true}, "w)|dh=|", "<W><I><D><H><I>|"},
+		{Options{Recovery: RecoverVerbatim, VerbatimOpen: "["}, "qea/ k/ai", "<q><e><a/>| [k/ai|"},
+	}
+
+	for _, tt := range tests {
+		var r recorder
+		w := NewSinkWriter(&r)
+		w.Options = tt.opts
+		w.WriteString(tt.beta)
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		if r.String() != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.beta, tt.want, r.String())
+		}
+	}
+}
+
+type failingSink struct{ recorder }
+
+func (failingSink) WriteBoundary() error { return errors.New("boundary") }
+
+func TestSinkError(t *testing.T) {
+	w := NewSinkWriter(&failingSink{})
+	w.WriteString("qea/ lo/gos")
+	if err := w.Flush(); err == nil || err.Error() != "boundary" {
+		t.Errorf("expected error from the sink, got %v", err)
+	}
+}
+
+func TestTextSink(t *testing.T) {
+	const beta = "*)/Hrh| w)|dh=|, {Lp. 5L} qea/"
+
+	for _, combining := range []bool{false, true} {
+		var ref, sb strings.Builder
+		w := NewWriter(&ref)
+		w.Combining = combining
+		w.WriteString(beta)
+		w.Flush()
+
+		w = NewSinkWriter(&TextSink{W: &sb, Combining: combining})
+		w.WriteString(beta)
+		w.Flush()
+
+		if sb.String() != ref.String() {
+			t.Errorf("expected %q, got %q", ref.String(), sb.String())
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package beta
+
+// A State is a snapshot of the parser state of a Decoder or Writer: the
+// pending symbol, including a pending asterisk and diacritics before its
+// letter, whether the input is inside a literal region, the runes held
+// back, the position and the statistics. The Options are not part of it.
+//
+// An editor can keep the State at the start of each line and, when a line
+// changes, Restore the State before it and parse from there instead of from
+// the start of the document.
+type State struct {
+	d Decoder
+}
+
+// State returns a snapshot of the parser state.
+func (d *Decoder) State() State {
+	return State{d.clone()}
+}
+
+// Restore returns the Decoder to the parser state s, which may come from
+// another Decoder. The Options are kept. A State may be restored any number
+// of times.
+func (d *Decoder) Restore(s State) {
+	opts, buf, dual, sink := d.Options, d.buf, d.dual, d.sink
+	*d = s.d.clone()
+	d.Options, d.buf, d.dual, d.sink = opts, buf, dual, sink
+}
+
+// clone returns a copy of d without Options that shares no memory with d.
+func (d *Decoder) clone() Decoder {
+	c := *d
+	c.Options = Options{}
+	c.buf = nil
+	c.sink = nil
+	c.held = append([]heldRune(nil), d.held...)
+	c.symSrc = append([]byte(nil), d.symSrc...)
+	c.marks = append([]byte(nil), d.marks...)
+	c.word = append([]byte(nil), d.word...)
+	c.src = append([]byte(nil), d.src...)
+	c.alt = append([]byte(nil), d.alt...)
+	c.altWord = append([]byte(nil), d.altWord...)
+	c.sinkWord = append([]Sym(nil), d.sinkWord...)
+	c.wordSeg.out = append([]byte(nil), d.wordSeg.out...)
+	c.lineSeg.out = append([]byte(nil), d.lineSeg.out...)
+	return c
+}
@@ -0,0 +1,47 @@
+package beta
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	var d Decoder
+
+	// A capital with its diacritics, but not its letter yet
+	out, _ := d.push(nil, '*')
+	out, _ = d.push(out, ')')
+	s := d.State()
+
+	for _, tt := range []struct{ rest, greek string }{{"a ", "Ἀ "}, {"h ", "Ἠ "}} {
+		d.Restore(s)
+		out = out[:0]
+		for _, r := range tt.rest {
+			out, _ = d.push(out, r)
+		}
+		if string(out) != tt.greek {
+			t.Errorf("%q: expected %q, got %q", tt.rest, tt.greek, out)
+		}
+	}
+	if d.pos.Offset != 4 {
+		t.Errorf("expected offset 4, got %d", d.pos.Offset)
+	}
+
+	// Inside a literal region, after an error; the output up to the error
+	// stays.
+	var sb strings.Builder
+	w := NewWriter(&sb)
+	w.WriteString("{Lab")
+	s = w.State()
+	if _, err := w.WriteString("L} k/"); err == nil {
+		t.Fatal("expected error for accent on consonant")
+	}
+	w.Restore(s)
+	w.WriteString(" k/L} qea/")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "ab  k/ θεά" {
+		t.Errorf("unexpected %q", sb.String())
+	}
+}
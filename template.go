@@ -0,0 +1,15 @@
+package beta
+
+// FuncMap returns conversion functions for use with text/template and
+// html/template (pass it to Template.Funcs):
+//
+//	{{beta "mh=nin"}}      Betacode to Greek (ToGreek)
+//	{{betacode .Greek}}    Greek to Betacode (FromGreek)
+//
+// A conversion error aborts template execution.
+func FuncMap() map[string]interface{} {
+	return map[string]interface{}{
+		"beta":     ToGreek,
+		"betacode": FromGreek,
+	}
+}
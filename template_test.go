@@ -0,0 +1,37 @@
+package beta
+
+import (
+	html "html/template"
+	"strings"
+	"testing"
+	text "text/template"
+)
+
+func TestFuncMap(t *testing.T) {
+	const src = `{{beta "mh=nin"}} {{betacode .}}`
+	const ref = "μῆνιν qea/"
+
+	var sb strings.Builder
+	tt := text.Must(text.New("t").Funcs(FuncMap()).Parse(src))
+	if err := tt.Execute(&sb, "θεά"); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != ref {
+		t.Error("text/template: expected '" + ref + "', got '" + sb.String() + "'")
+	}
+
+	sb.Reset()
+	ht := html.Must(html.New("t").Funcs(FuncMap()).Parse(src))
+	if err := ht.Execute(&sb, "θεά"); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != ref {
+		t.Error("html/template: expected '" + ref + "', got '" + sb.String() + "'")
+	}
+
+	sb.Reset()
+	tt = text.Must(text.New("t").Funcs(FuncMap()).Parse(`{{beta "k/"}}`))
+	if err := tt.Execute(&sb, nil); err == nil {
+		t.Error("expected conversion error")
+	}
+}
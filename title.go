@@ -0,0 +1,49 @@
+package beta
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Title returns greek with the first letter of each word capitalised.
+// Polytonic letters are handled as a whole: ἄ becomes Ἄ and ᾠ becomes ᾨ,
+// with the diacritics moved to the precombined capital. A word starts with
+// a letter that does not follow another letter or combining mark.
+func Title(greek string) string {
+	var sb strings.Builder
+	inWord := false
+
+	for s := greek; len(s) > 0; {
+		n := clusterLen(s)
+		cluster := s[:n]
+		s = s[n:]
+
+		r, _ := utf8.DecodeRuneInString(cluster)
+		letter := unicode.IsLetter(r)
+		if letter && !inWord {
+			cluster = titleCluster(cluster)
+		}
+		inWord = letter
+
+		sb.WriteString(cluster)
+	}
+
+	return sb.String()
+}
+
+// titleCluster capitalises the base letter of a grapheme cluster.
+func titleCluster(cluster string) string {
+	nfc := norm.NFC.IsNormalString(cluster)
+
+	d := norm.NFD.String(cluster)
+	r, n := utf8.DecodeRuneInString(d)
+	d = string(unicode.ToTitle(r)) + d[n:]
+
+	if nfc {
+		return norm.NFC.String(d)
+	}
+	return d
+}
@@ -0,0 +1,28 @@
+package beta
+
+import (
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"μῆνιν ἄειδε θεά", "Μῆνιν Ἄειδε Θεά"},
+		{"ᾠδῇ «ἀνήρ»", "ᾨδῇ «Ἀνήρ»"},
+		{"α\u0313\u0301ειδε", "Α\u0313\u0301ειδε"},
+		{"δ᾽ ἐτελείετο", "Δ᾽ Ἐτελείετο"},
+	}
+
+	for _, tt := range tests {
+		if s := Title(tt.in); s != tt.out {
+			t.Errorf("Title(%q): expected %q, got %q", tt.in, tt.out, s)
+		}
+	}
+
+	if s := Title("ἄειδε"); !norm.NFC.IsNormalString(s) {
+		t.Errorf("expected NFC output, got %q", s)
+	}
+}
@@ -0,0 +1,139 @@
+package beta
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A TokenKind classifies a Token.
+type TokenKind int
+
+const (
+	WordToken    TokenKind = iota // Greek word
+	PunctToken                    // Any other non-space character
+	LiteralToken                  // Literal region, without delimiters
+)
+
+// A Token is a word or punctuation mark of converted text, with its place
+// in the Betacode source.
+type Token struct {
+	Kind  TokenKind
+	Greek string
+	Start int // Byte offset of the token in the Betacode source
+	End   int // Byte offset after the token
+}
+
+// A Tokenizer reads Betacode and splits the converted text into tokens for
+// stand-off annotation: every word is a token, as is every other character
+// except whitespace, which separates tokens, and every literal region. Its
+// interface follows bufio.Scanner.
+type Tokenizer struct {
+	// The Betacode is read as by a Decoder with Options, which must be
+	// set before the first Scan. The Recovery, Handler, Map, Marker,
+	// OnWord, OnLine, Warn and VerifyNFC are not used.
+	Options
+
+	r       *bufio.Reader
+	d       *Decoder
+	buf     []byte
+	toks    []Token // Tokens read, but not scanned yet
+	tok     Token
+	lit     strings.Builder // Literal region being read
+	litFrom int             // Start of the literal region
+	literal bool
+	pending error // Error after toks
+	eof     bool
+	err     error
+}
+
+// NewTokenizer returns a Tokenizer reading Betacode from r.
+func NewTokenizer(r io.Reader) *Tokenizer {
+	return &Tokenizer{r: bufio.NewReader(r)}
+}
+
+// Scan advances to the next token, which is then available through Token.
+// It returns false at the end of input or after an error.
+func (t *Tokenizer) Scan() bool {
+	if t.d == nil {
+		t.d = &Decoder{Options: pieceOptions(t.Options), onPiece: t.piece}
+	}
+
+	for len(t.toks) == 0 {
+		switch {
+		case t.err != nil:
+			return false
+		case t.pending != nil:
+			t.err = t.pending
+			return false
+		case t.eof:
+			return false
+		}
+
+		r, size, err := t.r.ReadRune()
+		if err == io.EOF {
+			t.eof = true
+			t.buf, _ = t.d.end(t.buf[:0])
+			if t.literal {
+				t.literal = false
+				t.toks = append(t.toks, Token{Kind: LiteralToken, Greek: t.lit.String(), Start: t.litFrom, End: t.d.pos.Offset})
+			}
+			continue
+		}
+		if err != nil {
+			t.err = err
+			return false
+		}
+		t.buf, _ = t.d.pushSize(t.buf[:0], r, size)
+	}
+
+	t.tok = t.toks[0]
+	t.toks = t.toks[1:]
+	return true
+}
+
+// piece makes tokens of the pieces of the Decoder.
+func (t *Tokenizer) piece(p piece) {
+	if t.pending != nil {
+		return
+	}
+
+	switch p.kind {
+	case pieceWord:
+		if p.err != nil {
+			t.pending = &SyntaxError{Pos: p.pos, Msg: p.err.Error()}
+			return
+		}
+		t.toks = append(t.toks, Token{Kind: WordToken, Greek: string(p.text), Start: p.in[0], End: p.in[1]})
+
+	case pieceText:
+		if r, _ := utf8.DecodeRune(p.text); !unicode.IsSpace(r) {
+			t.toks = append(t.toks, Token{Kind: PunctToken, Greek: string(p.text), Start: p.in[0], End: p.in[1]})
+		}
+
+	case pieceLiteral:
+		t.lit.Write(p.text)
+
+	case pieceDelim:
+		if !t.literal {
+			t.lit.Reset()
+			t.litFrom = p.in[0]
+		} else {
+			t.toks = append(t.toks, Token{Kind: LiteralToken, Greek: t.lit.String(), Start: t.litFrom, End: p.in[1]})
+		}
+		t.literal = !t.literal
+	}
+}
+
+// Token returns the token found by the last call to Scan.
+func (t *Tokenizer) Token() Token {
+	return t.tok
+}
+
+// Err returns the first error encountered, which is a *SyntaxError for
+// invalid Betacode.
+func (t *Tokenizer) Err() error {
+	return t.err
+}
@@ -0,0 +1,119 @@
+package beta
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenizer(t *testing.T) {
+	const src = "mh=nin a)/eide, qea/"
+
+	ref := []Token{
+		{WordToken, "μῆνιν", 0, 6},
+		{WordToken, "ἄειδε", 7, 14},
+		{PunctToken, ",", 14, 15},
+		{WordToken, "θεά", 16, 20},
+	}
+
+	tz := NewTokenizer(strings.NewReader(src))
+	var got []Token
+	for tz.Scan() {
+		got = append(got, tz.Token())
+	}
+	if tz.Err() != nil {
+		t.Fatal(tz.Err())
+	}
+
+	if len(got) != len(ref) {
+		t.Fatal("expected", ref, "got", got)
+	}
+	for i := range ref {
+		if got[i] != ref[i] {
+			t.Errorf("expected %v, got %v", ref[i], got[i])
+		}
+		if src[got[i].Start:got[i].End] == "" {
+			t.Errorf("empty source range for %v", got[i])
+		}
+	}
+
+	tz = NewTokenizer(strings.NewReader("qea/ k/"))
+	for tz.Scan() {
+	}
+	if _, ok := tz.Err().(*SyntaxError); !ok {
+		t.Error("expected *SyntaxError, got", tz.Err())
+	}
+}
+
+func TestTokenizerLiteral(t *testing.T) {
+	tz := NewTokenizer(strings.NewReader("qea/ {Lsee p. 5L} lo/gos"))
+	var got []Token
+	for tz.Scan() {
+		got = append(got, tz.Token())
+	}
+
+	ref := []Token{
+		{WordToken, "θεά", 0, 4},
+		{LiteralToken, "see p. 5", 5, 17},
+		{WordToken, "λόγος", 18, 24},
+	}
+	if len(got) != len(ref) {
+		t.Fatal("expected", ref, "got", got)
+	}
+	for i := range ref {
+		if got[i] != ref[i] {
+			t.Errorf("expected %v, got %v", ref[i], got[i])
+		}
+	}
+}
+
+func TestTokenizerDelims(t *testing.T) {
+	tz := NewTokenizer(strings.NewReader("qea/ {Lx} Ls.5Ls lo/gos"))
+	tz.LiteralOpen, tz.LiteralClose = "Ls", "Ls"
+	var got []Token
+	for tz.Scan() {
+		got = append(got, tz.Token())
+	}
+
+	ref := []Token{
+		{WordToken, "θεά", 0, 4},
+		{PunctToken, "{", 5, 6},
+		{WordToken, "Λχ", 6, 8},
+		{PunctToken, "}", 8, 9},
+		{LiteralToken, ".5", 10, 16},
+		{WordToken, "λόγος", 17, 23},
+	}
+	if len(got) != len(ref) {
+		t.Fatal("expected", ref, "got", got)
+	}
+	for i := range ref {
+		if got[i] != ref[i] {
+			t.Errorf("expected %v, got %v", ref[i], got[i])
+		}
+	}
+}
+
+func TestTokenizerOptions(t *testing.T) {
+	tz := NewTokenizer(strings.NewReader("*LO/GOS2 <<A/>> QEvA/"))
+	tz.Options = Options{Dialect: DialectTLG, Digits: DigitsCodes, LiteralOpen: "<<", LiteralClose: ">>", Rules: []Rule{{From: 'v'}}}
+	var got []Token
+	for tz.Scan() {
+		got = append(got, tz.Token())
+	}
+	if tz.Err() != nil {
+		t.Fatal(tz.Err())
+	}
+
+	ref := []Token{
+		{WordToken, "Λόγος", 0, 8},
+		{LiteralToken, "A/", 9, 15},
+		{WordToken, "θεά", 16, 21},
+	}
+	if len(got) != len(ref) {
+		t.Fatal("expected", ref, "got", got)
+	}
+	for i := range ref {
+		if got[i] != ref[i] {
+			t.Errorf("expected %v, got %v", ref[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package beta
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// A Word is a parsed Betacode word: a run of Betacode characters.
+type Word struct {
+	Source string   // Betacode source
+	Pos    Position // Start in the source
+	Syms   []Sym
+}
+
+// Greek returns the word as precombined Greek.
+func (w Word) Greek() string {
+	var sb strings.Builder
+	for _, sym := range w.Syms {
+		sb.WriteString(sym.PrecombinedString())
+	}
+	return sb.String()
+}
+
+// Words parses the words of Betacode source. Everything between words, like
+// whitespace and punctuation, is skipped. At the first word that fails to
+// parse, Words returns the words so far and a *SyntaxError.
+func Words(src string) ([]Word, error) {
+	var words []Word
+	var err error
+
+	scanPieces(Options{}, src, nil, func(p piece) bool {
+		if p.kind != pieceWord {
+			return true
+		}
+		if p.err != nil {
+			err = &SyntaxError{Pos: p.pos, Msg: p.err.Error()}
+			return false
+		}
+		syms := append([]Sym(nil), p.syms...)
+		words = append(words, Word{Source: src[p.in[0]:p.in[1]], Pos: p.pos, Syms: syms})
+		return true
+	})
+	return words, err
+}
+
+// ConvertAt converts only the word of the Betacode text that contains the
+// byte offset, or ends at it, as for the cursor of an editor, and returns
+// its Greek and its start and end offsets. The text around the word decides
+// like in a whole conversion whether it is in a literal region and whether
+// a sigma at its end is final. If there is no word at offset, ConvertAt
+// returns "" and offset as both start and end. An invalid word is reported
+// as a *SyntaxError.
+func ConvertAt(text string, offset int) (greek string, start, end int, err error) {
+	return ConvertAtOptions(text, offset, Options{})
+}
+
+// ConvertAtOptions is like ConvertAt, but reads the text as a Decoder with
+// opts does, so that the word agrees with the conversion of the whole text.
+// The Recovery, Handler, Map, Marker, OnWord, OnLine, Warn and VerifyNFC
+// of opts are not used.
+func ConvertAtOptions(text string, offset int, opts Options) (greek string, start, end int, err error) {
+	start, end = offset, offset
+	scanPieces(opts, text, nil, func(p piece) bool {
+		if p.kind != pieceWord || p.in[1] < offset {
+			return p.in[0] <= offset
+		}
+		if p.in[0] > offset {
+			return false
+		}
+
+		start, end = p.in[0], p.in[1]
+		if p.err != nil {
+			err = &SyntaxError{Pos: p.pos, Msg: p.err.Error()}
+		} else {
+			greek = string(p.text)
+		}
+		return false
+	})
+	return greek, start, end, err
+}
+
+// IterWordsBytes calls fn with the source and the precombined Greek of each
+// word of the Betacode b, as Words finds them, until fn returns false. A word
+// that fails to parse is passed with nil greek. IterWordsBytes does not
+// allocate per word, for indexing large corpora: word is part of b, and
+// greek is a buffer that is reused, valid only until fn returns.
+func IterWordsBytes(b []byte, fn func(word []byte, greek []byte) bool) {
+	scanPieces(Options{}, "", b, func(p piece) bool {
+		if p.kind != pieceWord {
+			return true
+		}
+		greek := p.text
+		if p.err != nil {
+			greek = nil
+		}
+		return fn(b[p.in[0]:p.in[1]], greek)
+	})
+}
+
+// A pieceKind tells what a piece is.
+type pieceKind int
+
+const (
+	pieceWord    pieceKind = iota // Word, valid or not
+	pieceText                     // Rune or marker that isn't Betacode
+	pieceLiteral                  // Rune of a literal region
+	pieceDelim                    // Delimiter of a literal region
+)
+
+// A piece is a part of Betacode as a Decoder with RecoverVerbatim reads it.
+// Its text and syms are only valid until the next piece.
+type piece struct {
+	kind pieceKind
+	in   [2]int   // Input range
+	pos  Position // Start of the input
+	text []byte   // Greek of a word, as far as it is valid, or the text
+	syms []Sym    // Symbols of a word, as far as it is valid
+	err  error    // Why the word is invalid, if it is
+}
+
+// pieceOptions returns opts for a Decoder that passes the pieces of its
+// input to onPiece: every word is read whole by RecoverVerbatim, and the
+// options for output to other places are dropped.
+func pieceOptions(opts Options) Options {
+	opts.Recovery = RecoverVerbatim
+	opts.Handler = nil
+	opts.VerbatimOpen, opts.VerbatimClose = "", ""
+	opts.Map = nil
+	opts.Marker = nil
+	opts.OnWord, opts.OnLine = nil, nil
+	opts.Warn = nil
+	opts.VerifyNFC = false
+	return opts
+}
+
+// scanPieces reads the Betacode s or, if b is not nil, b with a Decoder
+// with opts and calls fn for each piece, until fn returns false.
+func scanPieces(opts Options, s string, b []byte, fn func(p piece) bool) {
+	d := decoders.Get().(*Decoder)
+	d.Reset()
+	d.Options = pieceOptions(opts)
+	stop := false
+	d.onPiece = func(p piece) {
+		stop = stop || !fn(p)
+	}
+
+	n := len(s)
+	if b != nil {
+		n = len(b)
+	}
+	for i := 0; i < n && !stop; {
+		var r rune
+		var size int
+		if b != nil {
+			r, size = utf8.DecodeRune(b[i:])
+		} else {
+			r, size = utf8.DecodeRuneInString(s[i:])
+		}
+		d.buf, _ = d.pushSize(d.buf[:0], r, size)
+		i += size
+	}
+	if !stop {
+		d.buf, _ = d.end(d.buf[:0])
+	}
+
+	d.Options = Options{}
+	d.onPiece = nil
+	decoders.Put(d)
+}
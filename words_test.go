@@ -0,0 +1,133 @@
+package beta
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	words, err := Words("mh=nin a)/eide,\nqea/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref := []struct {
+		src, greek string
+		pos        Position
+	}{
+		{"mh=nin", "μῆνιν", Position{0, 1, 1}},
+		{"a)/eide", "ἄειδε", Position{7, 1, 8}},
+		{"qea/", "θεά", Position{16, 2, 1}},
+	}
+
+	if len(words) != len(ref) {
+		t.Fatal("expected", len(ref), "words, got", words)
+	}
+	for i, w := range words {
+		if w.Source != ref[i].src || w.Greek() != ref[i].greek || w.Pos != ref[i].pos {
+			t.Errorf("expected %v, got %s %s %v", ref[i], w.Source, w.Greek(), w.Pos)
+		}
+	}
+
+	words, err = Words("lo/gos k/ qea/")
+	if len(words) != 1 || err == nil {
+		t.Error("expected one word and an error, got", words, err)
+	}
+}
+
+func TestIterWordsBytes(t *testing.T) {
+	src := "mh=nin a)/eide,\nqea/ k/ {Lqea/L} lo/gos-"
+	var got []string
+	IterWordsBytes([]byte(src), func(word, greek []byte) bool {
+		got = append(got, string(word)+" "+string(greek))
+		return true
+	})
+
+	ref := []string{
+		"mh=nin \u03BC\u1FC6\u03BD\u03B9\u03BD",
+		"a)/eide \u1F04\u03B5\u03B9\u03B4\u03B5",
+		"qea/ \u03B8\u03B5\u03AC",
+		"k/ ",
+		"lo/gos \u03BB\u03CC\u03B3\u03BF\u03C3",
+	}
+	if strings.Join(got, "|") != strings.Join(ref, "|") {
+		t.Errorf("expected %q, got %q", ref, got)
+	}
+
+	n := 0
+	IterWordsBytes([]byte(src), func(word, greek []byte) bool {
+		n++
+		return n < 2
+	})
+	if n != 2 {
+		t.Errorf("expected to stop after 2 words, got %d", n)
+	}
+
+	corpus := []byte(strings.Repeat("mh=nin a)/eide qea/ ", 100))
+	allocs := testing.AllocsPerRun(10, func() {
+		IterWordsBytes(corpus, func(word, greek []byte) bool { return true })
+	})
+	if allocs > 4 {
+		t.Errorf("expected few allocations for 300 words, got %v", allocs)
+	}
+}
+
+func TestConvertAt(t *testing.T) {
+	text := "mh=nin a)/eide, qea/ lo/gos-tis {Lqea/L} k/"
+	tests := []struct {
+		offset     int
+		greek      string
+		start, end int
+	}{
+		{0, "\u03BC\u1FC6\u03BD\u03B9\u03BD", 0, 6},
+		{3, "\u03BC\u1FC6\u03BD\u03B9\u03BD", 0, 6},
+		{14, "\u1F04\u03B5\u03B9\u03B4\u03B5", 7, 14},
+		{15, "", 15, 15},
+		{18, "\u03B8\u03B5\u03AC", 16, 20},
+		{23, "\u03BB\u03CC\u03B3\u03BF\u03C3", 21, 27}, // Medial before a hyphen
+		{35, "", 35, 35},                               // Literal region
+	}
+
+	for _, tt := range tests {
+		greek, start, end, err := ConvertAt(text, tt.offset)
+		if err != nil || greek != tt.greek || start != tt.start || end != tt.end {
+			t.Errorf("%d: expected %q %d-%d, got %q %d-%d, %v", tt.offset, tt.greek, tt.start, tt.end, greek, start, end, err)
+		}
+	}
+
+	_, start, end, err := ConvertAt(text, len(text)-1)
+	var serr *SyntaxError
+	if !errors.As(err, &serr) || start != len(text)-2 || end != len(text) {
+		t.Errorf("expected a syntax error for k/, got %v at %d-%d", err, start, end)
+	}
+}
+
+func TestConvertAtOptions(t *testing.T) {
+	opts := Options{Dialect: DialectTLG, Digits: DigitsCodes, LiteralOpen: "<<", LiteralClose: ">>", Rules: []Rule{{From: 'v'}}}
+	text := "*LO/GOS2 <<A/>> QEvA/ {LKAI\\"
+	whole, err := ConvertAppend(nil, []byte(text), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		offset     int
+		greek      string
+		start, end int
+	}{
+		{3, "\u039B\u03CC\u03B3\u03BF\u03C2", 0, 8}, // s2 is final
+		{11, "", 11, 11},                         // Literal region
+		{17, "\u03B8\u03B5\u03AC", 16, 21},       // v is dropped
+		{27, "\u03BB\u03BA\u03B1\u1F76", 23, 28}, // {L is no delimiter
+	}
+	for _, tt := range tests {
+		greek, start, end, err := ConvertAtOptions(text, tt.offset, opts)
+		if err != nil || greek != tt.greek || start != tt.start || end != tt.end {
+			t.Errorf("%d: expected %q %d-%d, got %q %d-%d, %v", tt.offset, tt.greek, tt.start, tt.end, greek, start, end, err)
+		}
+		if !strings.Contains(string(whole), greek) {
+			t.Errorf("%d: %q is not in the whole conversion %q", tt.offset, greek, whole)
+		}
+	}
+}
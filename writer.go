@@ -3,101 +3,329 @@ package beta
 import (
 	"bufio"
 	"io"
-	"strings"
+	"io/ioutil"
+	"unicode/utf8"
 )
 
-
 // Valid Betacode characters in string form.
 const validCodes = `ABGDEVZHQIKLMNCOPRJSTUFXYWabgdevzhqiklmncoprjstufxyw/\=)(|+*`
 
-// Writer converts Betacode to UTF-8 Greek.
+// Writer converts Betacode to UTF-8 Greek. Everything else, including all
+// whitespace and line breaks, is copied unchanged, so verse keeps its
+// indentation and stanza breaks without a special mode.
 type Writer struct {
-	// Precombined UTF-8 (NFC) if false, combining diacritics otherwise.
-	Combining bool
+	Options
+
+	// Skip the internal buffer if the underlying writer implements
+	// io.StringWriter, which in-memory writers like bytes.Buffer and
+	// strings.Builder (and buffered writers) do. Greek output is then
+	// written to it directly, without a copy to the internal buffer.
+	Unbuffered bool
+
+	dec     Decoder
+	w       io.Writer
+	wbuf    []byte    // Output not yet written to w
+	direct  io.Writer // underlying writer if it is an io.StringWriter
+	buf     []byte
+	written int    // Bytes of output
+	err     error  // First error
+	partial []byte // Start of a rune split across Writes
 
-	w *bufio.Writer
+	// Combining output of a Writer from NewDualWriter
+	alt    *bufio.Writer
+	altDst io.Writer
 }
 
+// Size of the internal buffer of NewWriter
+const defaultBufSize = 4096
+
 func NewWriter(w io.Writer) *Writer {
-	return &Writer{w: bufio.NewWriter(w)}
+	return NewWriterBuffer(w, make([]byte, 0, defaultBufSize))
+}
+
+// NewWriterBuffer is like NewWriter, but uses buf, whose capacity is the
+// size of the internal buffer, so that buffers can be pooled. If buf has no
+// capacity, a buffer of the default size is allocated. The Writer owns buf
+// until it is no longer used.
+func NewWriterBuffer(w io.Writer, buf []byte) *Writer {
+	if cap(buf) == 0 {
+		buf = make([]byte, 0, defaultBufSize)
+	}
+	bw := &Writer{w: w, wbuf: buf[:0]}
+	if _, ok := w.(io.StringWriter); ok {
+		bw.direct = w
+	}
+	return bw
+}
+
+// NewDualWriter returns a Writer that writes the Greek both precombined to
+// nfc and with combining diacritics to combining, converting the Betacode
+// only once. Options.Combining is ignored; Options.Canonical applies to both
+// forms.
+func NewDualWriter(nfc, combining io.Writer) *Writer {
+	w := NewWriter(nfc)
+	w.alt = bufio.NewWriter(combining)
+	w.altDst = combining
+	w.dec.dual = true
+	return w
 }
 
-// Write converts Betacode in p to Greek. The last symbol must be complete: this Writer
-// does not retain partial symbols between writes. The Writer must also be Flushed
-// for the Write to take effect.
+// NewSinkWriter returns a Writer that writes its output to s instead of as
+// UTF-8. The options Combining, Canonical, Sigma's lunate forms and Map
+// are up to the Sink, and Stats doesn't count Bytes.
+func NewSinkWriter(s Sink) *Writer {
+	w := &Writer{w: ioutil.Discard}
+	w.dec.sink = s
+	return w
+}
+
+// Write converts Betacode in p to Greek. A symbol at the end of p is held back
+// until the next Write or Flush shows that it is complete, as are the bytes
+// of a UTF-8 sequence that p ends in the middle of; Flush reads them as
+// invalid UTF-8 if no Write completes them. The Writer must also
+// be Flushed for the Write to take effect. The returned n counts the bytes of p
+// that were consumed.
+//
+// Once an error has occurred, be it invalid Betacode or a failed write, all
+// further Writes and Flushes return it; see Err. Use Options.Handler or
+// Options.Recovery to go on after invalid Betacode.
 func (w *Writer) Write(p []byte) (n int, err error) {
-	s := string(p)
-	total := 0
-	var sym Sym
-
-	// Output sym and reset it.
-	wsym := func() error {
-		var t string
-
-		if w.Combining {
-			t = sym.CombiningString()
-		} else {
-			t = sym.PrecombinedString()
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.dec.Options = w.Options
+
+	if len(w.partial) > 0 {
+		var head [utf8.UTFMax]byte
+		m, err := w.completeRune(head[:copy(head[:], p)])
+		if err != nil || len(w.partial) > 0 {
+			return m, err
 		}
+		n = m
+	}
 
-		n, err := w.w.WriteString(t)
-		total += n
-		if err != nil {
-			return err
+	for n < len(p) {
+		if !utf8.FullRune(p[n:]) {
+			w.partial = append(w.partial, p[n:]...)
+			return len(p), nil
+		}
+		r, size := utf8.DecodeRune(p[n:])
+		if err := w.push(r, size); err != nil {
+			return n, err
 		}
+		n += size
+	}
 
-		sym.Reset()
-		return nil
+	return n, nil
+}
+
+// WriteString is like Write, but converts a string without copying it.
+func (w *Writer) WriteString(s string) (n int, err error) {
+	if w.err != nil {
+		return 0, w.err
 	}
+	w.dec.Options = w.Options
 
-	for _, r := range s {
-		// End of word detected
-		if !strings.ContainsRune(validCodes, r) {
-			// Set sigma to final variant.
-			if sym.Base == 's' {
-				sym.Base = 'j'
-			}
+	if len(w.partial) > 0 {
+		var head [utf8.UTFMax]byte
+		m, err := w.completeRune(head[:copy(head[:], s)])
+		if err != nil || len(w.partial) > 0 {
+			return m, err
+		}
+		n = m
+	}
 
-			// Output and clear symbol.
-			err := wsym()
-			if err != nil {
-				return total, err
-			}
+	for n < len(s) {
+		if !utf8.FullRuneInString(s[n:]) {
+			w.partial = append(w.partial, s[n:]...)
+			return len(s), nil
+		}
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if err := w.push(r, size); err != nil {
+			return n, err
+		}
+		n += size
+	}
 
-			// Output the non-code rune.
-			n, err := w.w.WriteRune(r)
-			total += n
-			if err != nil {
-				return total, err
-			}
+	return n, nil
+}
 
-			continue
+// completeRune converts the runes that start in the bytes held back from
+// the last Write, as far as head, the start of the next one, completes
+// them. It returns the number of bytes of head they take; if they are
+// still incomplete, all of head is held back as well.
+func (w *Writer) completeRune(head []byte) (n int, err error) {
+	var buf [2 * utf8.UTFMax]byte
+	b := append(buf[:0], w.partial...)
+	b = append(b, head...)
+
+	held := len(w.partial)
+	w.partial = w.partial[:0]
+	i := 0
+	for i < held {
+		if !utf8.FullRune(b[i:]) {
+			w.partial = append(w.partial, b[i:]...)
+			return len(head), nil
+		}
+		r, size := utf8.DecodeRune(b[i:])
+		if err := w.push(r, size); err != nil {
+			return 0, err
 		}
+		i += size
+	}
+	return i - held, nil
+}
 
-	nextsym:
-		ok := sym.Add(r)
+// push converts r, decoded from size bytes, and writes the output.
+func (w *Writer) push(r rune, size int) error {
+	var err error
 
-		if !ok {
-			// Proper error
-			if sym.Err() != nil {
-				return total, err
-			}
+	w.buf, err = w.dec.pushSize(w.buf[:0], r, size)
+	if w.dec.sinkErr != nil {
+		err = w.dec.sinkErr
+	} else if werr := w.out(w.buf); werr != nil {
+		err = werr
+	} else if werr := w.outAlt(); werr != nil {
+		err = werr
+	}
+	w.err = err
+	return err
+}
 
-			// We encountered the base rune of the next symbol. Output the current symbol,
-			// reset sym, and add the base for the next sym.
-			err := wsym()
-			if err != nil {
-				return total, err
+// out writes Greek output to the internal buffer or directly to the
+// underlying writer.
+func (w *Writer) out(p []byte) error {
+	if len(p) == 0 {
+		return nil
+	}
+
+	w.written += len(p)
+	if w.Unbuffered && w.direct != nil {
+		if err := w.flushBuf(); err != nil {
+			return err
+		}
+		n, err := w.direct.Write(p)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		return err
+	}
+
+	if len(w.wbuf)+len(p) > cap(w.wbuf) {
+		if err := w.flushBuf(); err != nil {
+			return err
+		}
+		if len(p) > cap(w.wbuf) {
+			n, err := w.w.Write(p)
+			if err == nil && n < len(p) {
+				err = io.ErrShortWrite
 			}
-			goto nextsym
+			return err
 		}
 	}
+	w.wbuf = append(w.wbuf, p...)
+	return nil
+}
 
-	err = wsym()
-	return total, err
+// flushBuf writes the internal buffer to the underlying writer.
+func (w *Writer) flushBuf() error {
+	if len(w.wbuf) == 0 {
+		return nil
+	}
+
+	n, err := w.w.Write(w.wbuf)
+	if err == nil && n < len(w.wbuf) {
+		err = io.ErrShortWrite
+	}
+	w.wbuf = w.wbuf[:0]
+	return err
 }
 
-// Flush flushes the underlying buffer.
+// outAlt writes the combining output of a dual Writer.
+func (w *Writer) outAlt() error {
+	if w.alt == nil || len(w.dec.alt) == 0 {
+		return nil
+	}
+	_, err := w.alt.Write(w.dec.alt)
+	w.dec.alt = w.dec.alt[:0]
+	return err
+}
+
+// Flush ends the input, writes out the symbol held back, if any, and flushes
+// the underlying buffer. A literal region stays open, as with Decoder.Flush.
 func (w *Writer) Flush() error {
-	return w.w.Flush()
+	if w.err != nil {
+		return w.err
+	}
+	w.dec.Options = w.Options
+
+	// Bytes of an incomplete rune are invalid UTF-8, each on its own.
+	for len(w.partial) > 0 {
+		w.partial = w.partial[1:]
+		if err := w.push(utf8.RuneError, 1); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	w.buf, err = w.dec.end(w.buf[:0])
+	if w.dec.sinkErr != nil {
+		err = w.dec.sinkErr
+	} else if werr := w.out(w.buf); werr != nil {
+		err = werr
+	} else if werr := w.outAlt(); werr != nil {
+		err = werr
+	} else if werr := w.flushBuf(); werr != nil {
+		err = werr
+	} else if w.alt != nil {
+		err = w.alt.Flush()
+	}
+
+	w.err = err
+	return err
+}
+
+// Err returns the first error that occurred in a Write or Flush, if any.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// Reset discards the pending input, the statistics and any error and makes
+// the Writer write to dst. The Options are kept. A Writer from NewDualWriter
+// goes on writing the combining form to the same writer.
+func (w *Writer) Reset(dst io.Writer) {
+	w.dec.Reset()
+	w.err = nil
+	w.partial = w.partial[:0]
+	w.w = dst
+	w.wbuf = w.wbuf[:0]
+	if w.alt != nil {
+		w.alt.Reset(w.altDst)
+	}
+	w.direct = nil
+	if _, ok := dst.(io.StringWriter); ok {
+		w.direct = dst
+	}
+	w.written = 0
+}
+
+// State returns a snapshot of the parser state. Output already converted
+// is not part of it, whether flushed or not.
+func (w *Writer) State() State {
+	return w.dec.State()
+}
+
+// Restore returns the Writer to the parser state s and clears any error.
+// The output already converted is kept.
+func (w *Writer) Restore(s State) {
+	w.dec.Restore(s)
+	w.err = nil
+}
+
+// Stats returns the counts of the conversion since the Writer was created
+// or Reset. Bytes counts the Greek output, whether flushed or not.
+func (w *Writer) Stats() Stats {
+	s := w.dec.stats
+	s.Bytes = w.written
+	return s
 }
@@ -3,6 +3,7 @@ package beta
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"testing"
 )
 
@@ -15,8 +16,303 @@ func TestWriter(t *testing.T) {
 	fmt.Fprint(w, "Mh=nin a)/eide, qea/, Phlhi+a/dew A)xilh=os ")
 	w.Flush()
 
-	// XXX Why does this always fail? They look the same. Is the Unicode normalisation not done correctly?
 	if buf.String() != ref {
 		t.Error("expected '" + ref + "', got '" + buf.String() + "'")
 	}
 }
+
+func TestWriterSplit(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	w.Write([]byte("lo/g"))
+	w.Write([]byte("os"))
+	w.Flush()
+
+	if buf.String() != "λόγος" {
+		t.Error("expected 'λόγος', got '" + buf.String() + "'")
+	}
+}
+
+func TestWriterBytewise(t *testing.T) {
+	const src = "{Lἄνδρα L}qea/ ἐννέπε"
+	const ref = "ἄνδρα θεά ἐννέπε"
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for i := 0; i < len(src); i++ {
+		if n, err := w.Write([]byte{src[i]}); n != 1 || err != nil {
+			t.Fatalf("byte %d: got %d, %v", i, n, err)
+		}
+	}
+	w.Flush()
+	if buf.String() != ref {
+		t.Errorf("expected %q, got %q", ref, buf.String())
+	}
+
+	buf.Reset()
+	w.Reset(&buf)
+	for i := 0; i < len(src); i++ {
+		w.WriteString(src[i : i+1])
+	}
+	w.Flush()
+	if buf.String() != ref {
+		t.Errorf("WriteString: expected %q, got %q", ref, buf.String())
+	}
+
+	// An incomplete rune at the end is invalid.
+	buf.Reset()
+	w.Reset(&buf)
+	w.Write([]byte("a\xce"))
+	w.Flush()
+	if buf.String() != "α\uFFFD" {
+		t.Errorf("expected %q, got %q", "α\uFFFD", buf.String())
+	}
+
+	// So is a lead byte that the next Write doesn't continue.
+	buf.Reset()
+	w.Reset(&buf)
+	w.Write([]byte("\xce"))
+	w.Write([]byte("a"))
+	w.Flush()
+	if buf.String() != "\uFFFDα" {
+		t.Errorf("expected %q, got %q", "\uFFFDα", buf.String())
+	}
+}
+
+func TestWriterLiteralFlush(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	// Flushing line by line keeps the literal region open.
+	for _, line := range []string{"qea/ {Lline one\n", "line two L} lo/gos\n"} {
+		w.WriteString(line)
+		w.Flush()
+	}
+
+	const ref = "θεά line one\nline two  λόγος\n"
+	if buf.String() != ref {
+		t.Errorf("expected %q, got %q", ref, buf.String())
+	}
+
+	w.Reset(&buf)
+	buf.Reset()
+	w.WriteString("{Lone")
+	w.Flush()
+	w.Reset(&buf)
+	w.WriteString("lo/gos")
+	w.Flush()
+	if buf.String() != "oneλόγος" {
+		t.Error("expected Reset to close the literal region, got '" + buf.String() + "'")
+	}
+}
+
+func TestWriterUnbuffered(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Unbuffered = true
+
+	w.WriteString("qea/ ")
+	if buf.String() != "θεά " {
+		t.Error("expected 'θεά ' before Flush, got '" + buf.String() + "'")
+	}
+}
+
+func TestWriterUnbufferedAllocs(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Grow(1 << 16)
+	w := NewWriter(&buf)
+	w.Unbuffered = true
+
+	allocs := testing.AllocsPerRun(100, func() {
+		w.WriteString("qea/ ")
+	})
+	if allocs > 0 {
+		t.Errorf("expected no allocations by unbuffered Writes, got %v", allocs)
+	}
+}
+
+func TestWriterBuffer(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriterBuffer(&buf, make([]byte, 0, 8))
+
+	w.WriteString("qea/ lo/gos ")
+	if buf.Len() == 0 || buf.Len() > 16 {
+		t.Errorf("expected part of the output before Flush, got %q", buf.String())
+	}
+	w.Flush()
+	if buf.String() != "θεά λόγος " {
+		t.Error("expected 'θεά λόγος ', got '" + buf.String() + "'")
+	}
+
+	buf.Reset()
+	w = NewWriterBuffer(&buf, nil)
+	w.WriteString("qea/")
+	w.Flush()
+	if buf.String() != "θεά" {
+		t.Error("expected 'θεά', got '" + buf.String() + "'")
+	}
+}
+
+// A shortWriter writes one byte less than it is given.
+type shortWriter struct{}
+
+func (shortWriter) Write(p []byte) (int, error) {
+	return len(p) - 1, nil
+}
+
+func TestWriterShortWrite(t *testing.T) {
+	// The output is larger than the buffer, so it is written directly.
+	w := NewWriterBuffer(shortWriter{}, make([]byte, 0, 1))
+	w.WriteString("qea/")
+	if err := w.Flush(); err != io.ErrShortWrite {
+		t.Errorf("expected io.ErrShortWrite, got %v", err)
+	}
+}
+
+func TestWriterStats(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Handler = func(err error, pos Position, source string) Action {
+		return Skip
+	}
+
+	w.WriteString("qea/ k/ai lo/gos")
+	w.Flush()
+
+	ref := Stats{Symbols: 10, Words: 3, Errors: 1, Bytes: buf.Len()}
+	if s := w.Stats(); s != ref {
+		t.Errorf("expected %+v, got %+v", ref, s)
+	}
+
+	buf.Reset()
+	w.Reset(&buf)
+	w.WriteString("a)/")
+	w.Flush()
+	if s := w.Stats(); s != (Stats{Symbols: 1, Words: 1, Bytes: buf.Len()}) {
+		t.Errorf("unexpected %+v after Reset", s)
+	}
+}
+
+func TestWriterSticky(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	_, err := w.WriteString("qea/ k/ai")
+	if err == nil {
+		t.Fatal("expected error for accent on consonant")
+	}
+	if w.Err() != err {
+		t.Errorf("Err: expected %v, got %v", err, w.Err())
+	}
+	if n, err2 := w.WriteString("lo/gos"); n != 0 || err2 != err {
+		t.Errorf("expected 0, %v from Write after error, got %d, %v", err, n, err2)
+	}
+	if err2 := w.Flush(); err2 != err {
+		t.Errorf("expected %v from Flush after error, got %v", err, err2)
+	}
+
+	w.Reset(&buf)
+	if w.Err() != nil {
+		t.Error("expected Reset to clear the error")
+	}
+}
+
+func TestWriterPlain(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Plain = true
+
+	w.WriteString("*(/Hrh| a)/eide, Phlhi+a/dew")
+	w.Flush()
+
+	const ref = "\u0397\u03C1\u1FC3 \u03B1\u03B5\u03B9\u03B4\u03B5, \u03A0\u03B7\u03BB\u03B7\u03CA\u03B1\u03B4\u03B5\u03C9"
+	if buf.String() != ref {
+		t.Errorf("expected %q, got %q", ref, buf.String())
+	}
+}
+
+func TestWriterLayout(t *testing.T) {
+	const verse = "  mh=nin a)/eide\n\t\tqea/,\r\n\n\n    Phlhi+a/dew  \n"
+	const ref = "  μῆνιν ἄειδε\n\t\tθεά,\r\n\n\n    Πηληϊάδεω  \n"
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WriteString(verse)
+	w.Flush()
+
+	if buf.String() != ref {
+		t.Errorf("expected %q, got %q", ref, buf.String())
+	}
+}
+
+func TestDualWriter(t *testing.T) {
+	var nfc, combining bytes.Buffer
+	w := NewDualWriter(&nfc, &combining)
+	w.Recovery = RecoverVerbatim
+
+	w.WriteString("a)/eide qea/, k/ lo/gos")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	const refNFC = "\u1F04ειδε θε\u03AC, k/ λ\u03CCγος"
+	const refComb = "\u03B1\u0313\u0301ειδε θεα\u0301, k/ λο\u0301γος"
+	if nfc.String() != refNFC {
+		t.Errorf("expected %q, got %q", refNFC, nfc.String())
+	}
+	if combining.String() != refComb {
+		t.Errorf("expected %q, got %q", refComb, combining.String())
+	}
+}
+
+func TestWriterEvents(t *testing.T) {
+	const src = "mh=nin a)/eide,\nqea/ lo/gos-tis k/\n\n{Lab L}"
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Handler = func(err error, pos Position, source string) Action { return Skip }
+
+	type event struct {
+		src, greek string
+		m          Mapping
+	}
+	var words, lines []event
+	w.OnWord = func(greek string, m Mapping) {
+		words = append(words, event{src[m.InStart:m.InEnd], greek, m})
+	}
+	w.OnLine = func(greek string, m Mapping) {
+		lines = append(lines, event{src[m.InStart:m.InEnd], greek, m})
+	}
+
+	// The word and line at the end of input are reported by Flush.
+	fmt.Fprint(w, src[:20])
+	fmt.Fprint(w, src[20:])
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(kind string, got []event, ref []string) {
+		if len(got) != len(ref)/2 {
+			t.Fatalf("expected %d %ss, got %v", len(ref)/2, kind, got)
+		}
+		for i, e := range got {
+			out := buf.String()[e.m.OutStart:e.m.OutEnd]
+			if e.src != ref[2*i] || e.greek != ref[2*i+1] || out != e.greek {
+				t.Errorf("%s %d: expected %q %q, got %q %q, output %q", kind, i, ref[2*i], ref[2*i+1], e.src, e.greek, out)
+			}
+		}
+	}
+	check("word", words, []string{
+		"mh=nin", "\u03BC\u1FC6\u03BD\u03B9\u03BD",
+		"a)/eide", "\u1F04\u03B5\u03B9\u03B4\u03B5",
+		"qea/", "\u03B8\u03B5\u03AC",
+		"lo/gos", "\u03BB\u03CC\u03B3\u03BF\u03C3",
+		"tis", "\u03C4\u03B9\u03C2",
+	})
+	check("line", lines, []string{
+		"mh=nin a)/eide,", "\u03BC\u1FC6\u03BD\u03B9\u03BD \u1F04\u03B5\u03B9\u03B4\u03B5,",
+		"qea/ lo/gos-tis k/", "\u03B8\u03B5\u03AC \u03BB\u03CC\u03B3\u03BF\u03C3-\u03C4\u03B9\u03C2 ",
+		"", "",
+		"{Lab L}", "ab ",
+	})
+}